@@ -0,0 +1,79 @@
+package ber_test
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ubavic/bas-celik/v2/card/ber"
+)
+
+type node struct {
+	address []uint64
+	indices []int
+	data    []byte
+}
+
+func nodes(tree *ber.BER) []node {
+	collected := []node{}
+	tree.Walk(func(address []uint64, indices []int, data []byte) {
+		collected = append(collected, node{address, indices, data})
+	})
+	return collected
+}
+
+// FuzzParseBER ensures that ParseBERWithLimits doesn't panic, respects the limits,
+// reports errors with offsets in the data, and that parsed trees are encoded and parsed again without changes.
+func FuzzParseBER(f *testing.F) {
+	f.Add([]byte{0x71, 0x07, 0x81, 0x05, 'B', 'G', '1', '2', '3'})
+	f.Add([]byte{0x71, 0x80, 0xA1, 0x80, 0x83, 0x01, 'O', 0x00, 0x00, 0x00, 0x00, 0x72, 0x00})
+	f.Add([]byte{0x9F, 0x33, 0x81, 0x03, 'S', 'R', 'B', 0x5F, 0x20, 0x82, 0x00, 0x01, 0xFF})
+	f.Add([]byte{0x7F, 0x81, 0x81, 0x01, 0x84, 0xFF, 0xFF, 0xFF, 0xFF})
+	f.Add([]byte{0x7F, 0x81, 0x81, 0x81, 0x01, 0x09, 0x9F, 0x81, 0x82, 0x83, 0x84, 0x05, 0x02, 'O', 'K'})
+
+	limits := ber.Limits{MaxDepth: 8, MaxNodes: 64}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tree, err := ber.ParseBERWithLimits(data, limits)
+		if err != nil {
+			var parseErr *ber.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("ParseBERWithLimits() error = %T, expected *ParseError", err)
+			}
+
+			if parseErr.Offset < 0 || parseErr.Offset > len(data) {
+				t.Fatalf("ParseError offset %d is outside of the data of length %d", parseErr.Offset, len(data))
+			}
+			return
+		}
+
+		parsed := nodes(tree)
+		if len(parsed) > limits.MaxNodes {
+			t.Fatalf("parsed %d nodes, limit is %d", len(parsed), limits.MaxNodes)
+		}
+
+		for _, n := range parsed {
+			if len(n.address) > limits.MaxDepth {
+				t.Fatalf("parsed node at depth %d, limit is %d", len(n.address), limits.MaxDepth)
+			}
+		}
+
+		// Trees with the tag 0 (like padding) can't be encoded
+		encoded, err := tree.Encode()
+		if err != nil {
+			return
+		}
+
+		reparsed, err := ber.ParseBERWithLimits(encoded, limits)
+		if err != nil {
+			t.Fatalf("ParseBERWithLimits() unexpected error for encoded tree: %v", err)
+		}
+
+		if !slices.EqualFunc(nodes(reparsed), parsed, func(a, b node) bool {
+			return slices.Equal(a.address, b.address) && slices.Equal(a.indices, b.indices) && bytes.Equal(a.data, b.data)
+		}) {
+			t.Fatalf("round trip changed the tree: %v, %v", tree, reparsed)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package tlv_test
+
+import (
+	"bytes"
+	"maps"
+	"testing"
+
+	"github.com/ubavic/bas-celik/v2/card/tlv"
+)
+
+// FuzzTLVRoundTrip ensures that the fields parsed with ParseTLV are encoded and parsed again without changes.
+func FuzzTLVRoundTrip(f *testing.F) {
+	f.Add([]byte{0x0A, 0x06, 0x03, 0x00, '1', '2', '3'})
+	f.Add([]byte{0x0B, 0x06, 0x02, 0x00, 'I', 'D', 0x0A, 0x06, 0x00, 0x00})
+	f.Add([]byte{0x01, 0x00, 0x05, 0x00, 0x01})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fields, err := tlv.ParseTLV(data)
+		if err != nil {
+			return
+		}
+
+		encoded, err := tlv.Encode(fields)
+		if err != nil {
+			t.Fatalf("Encode() unexpected error: %v", err)
+		}
+
+		parsed, err := tlv.ParseTLV(encoded)
+		if err != nil {
+			t.Fatalf("ParseTLV() unexpected error for encoded fields: %v", err)
+		}
+
+		if !maps.EqualFunc(parsed, fields, bytes.Equal) {
+			t.Fatalf("round trip changed the fields: %v, %v", fields, parsed)
+		}
+	})
+}
@@ -30,7 +30,7 @@ func FuzzIDDocumentBuildPdf(f *testing.F) {
 		idx := crc32.ChecksumIEEE([]byte(docType)) % uint32(len(docTypes))
 		normalizedType := docTypes[idx]
 
-		doc := document.IdDocument{
+		doc := document.IDDocument{
 			Portrait:             seedImage,
 			DocumentType:         normalizedType,
 			GivenName:            givenName,
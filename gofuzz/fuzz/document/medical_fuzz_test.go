@@ -30,7 +30,7 @@ func FuzzMedicalDocumentBuildPdf(f *testing.F) {
 			DateOfBirth:            dob,
 			InsuranceStartDate:     "2020-01-01",
 			InsuranceDescription:   "desc",
-			CardId:                 cardID,
+			CardID:                 cardID,
 			InsurantNumber:         "11111111111",
 			PersonalNumber:         "22222222222",
 			InsuranceBasisRZZO:     "basis",
@@ -40,7 +40,7 @@ func FuzzMedicalDocumentBuildPdf(f *testing.F) {
 			Gender:                 "F",
 			CarrierFamilyMember:    true,
 			CarrierRelationship:    "member",
-			CarrierIdNumber:        "33333333333",
+			CarrierIDNumber:        "33333333333",
 			CarrierInsurantNumber:  "44444444444",
 			CarrierGivenName:       givenName,
 			CarrierFamilyName:      familyName,
@@ -49,7 +49,7 @@ func FuzzMedicalDocumentBuildPdf(f *testing.F) {
 			TaxpayerName:           place,
 			TaxpayerResidence:      place,
 			TaxpayerNumber:         "55555555555",
-			TaxpayerIdNumber:       "66666666666",
+			TaxpayerIDNumber:       "66666666666",
 			TaxpayerActivityCode:   "123",
 		}
 
@@ -33,7 +33,7 @@ func FuzzVehicleDocumentBuildPdf(f *testing.F) {
 			UsersPersonalNo:             "3210987654321",
 			VehicleMake:                 vehicle,
 			VehicleType:                 vehicle,
-			VehicleIdNumber:             reg + "VIN",
+			VehicleIDNumber:             reg + "VIN",
 			VehicleCategory:             "M1",
 			VehicleMass:                 "1500",
 			MaximumPermissibleLadenMass: "2000",
@@ -42,7 +42,7 @@ func FuzzVehicleDocumentBuildPdf(f *testing.F) {
 			TypeOfFuel:                  "E",
 			DateOfFirstRegistration:     "2019-01-01",
 			EngineCapacity:              "1600",
-			EngineIdNumber:              "ENG" + reg,
+			EngineIDNumber:              "ENG" + reg,
 			MaximumNetPower:             "100",
 			ColourOfVehicle:             "Blue",
 			VehicleLoad:                 "500",
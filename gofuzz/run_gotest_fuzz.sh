@@ -8,16 +8,21 @@ if [ -d "./bas-celik" ]; then
     CODE_PATH="./bas-celik"
     RESULTS_DIR="./gofuzz/"
     FUZZ_TESTS_DIR="./gofuzz/fuzz/"
+    PATCH_DIR="./patch/"
 elif [ -d "../bas-celik" ]; then
     # Running from gofuzz folder
     CODE_PATH="../bas-celik"
     RESULTS_DIR="./"
     FUZZ_TESTS_DIR="./fuzz/"
+    PATCH_DIR="../patch/"
 else
     echo "Error: Could not find bas-celik directory"
     exit 1
 fi
 
+echo "Applying patches to codebase..."
+"$PATCH_DIR"/apply_patches.sh "$CODE_PATH" || exit 1
+
 echo "Moving fuzz tests into codebase (overwriting existing)..."
 rsync -av --include='*/' --include='*.go' --exclude='*' "$FUZZ_TESTS_DIR"/ "$CODE_PATH"/
 
@@ -25,15 +30,19 @@ cd $CODE_PATH
 echo "Preparing project..."
 go mod tidy
 RESULTS_DIR_TMP="../$RESULTS_DIR"
+# Each target is given with its package
 FUZZ_TARGETS=(
-    "FuzzIDDocumentBuildPdf"
-    "FuzzMedicalDocumentBuildPdf"
-    "FuzzVehicleDocumentBuildPdf"
+    "FuzzIDDocumentBuildPdf ./document"
+    "FuzzMedicalDocumentBuildPdf ./document"
+    "FuzzVehicleDocumentBuildPdf ./document"
+    "FuzzTLVRoundTrip ./card/tlv"
+    "FuzzParseBER ./card/ber"
 )
 
-for fuzz_name in "${FUZZ_TARGETS[@]}"; do
+for fuzz_target in "${FUZZ_TARGETS[@]}"; do
+    read -r fuzz_name fuzz_package <<< "$fuzz_target"
     echo "Starting fuzz test ${fuzz_name}..."
-    go test -run=^$ -fuzz="${fuzz_name}" -fuzztime=30s ./document > "${RESULTS_DIR_TMP}/gofuzz_out_${fuzz_name}.txt"
+    go test -run=^$ -fuzz="^${fuzz_name}\$" -fuzztime=30s "$fuzz_package" > "${RESULTS_DIR_TMP}/gofuzz_out_${fuzz_name}.txt"
     echo "Fuzz test ${fuzz_name} completed. Results:"
     tail -n 2 "${RESULTS_DIR_TMP}/gofuzz_out_${fuzz_name}.txt"
 done
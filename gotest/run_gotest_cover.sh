@@ -7,11 +7,13 @@ if [ -d "./bas-celik" ]; then
     CODE_PATH="./bas-celik"
     RESULTS_DIR="./gotest/"
     UNIT_TESTS_DIR="./gotest/unit/"
+    PATCH_DIR="./patch/"
 elif [ -d "../bas-celik" ]; then
     # Running from gotest folder
     CODE_PATH="../bas-celik"
     RESULTS_DIR="./"
     UNIT_TESTS_DIR="./unit/"
+    PATCH_DIR="../patch/"
 else
     echo "Error: Could not find bas-celik directory"
     exit 1
@@ -30,6 +32,9 @@ go tool cover -html=cover.before.out -o $RESULTS_DIR_TMP/cover_before.html
 rm cover.before.out
 cd -
 
+echo "Applying patches to codebase..."
+"$PATCH_DIR"/apply_patches.sh "$CODE_PATH" || exit 1
+
 echo "Adding new unit tests into codebase (overwriting existing)..."
 # Copy Go tests preserving relative paths (e.g., unit/card -> bas-celik/card)
 rsync -av --include='*/' --include='*.go' --exclude='*' "$UNIT_TESTS_DIR"/ "$CODE_PATH"/
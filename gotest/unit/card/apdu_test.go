@@ -0,0 +1,43 @@
+package card
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func Test_buildAPDU(t *testing.T) {
+	testCases := []struct {
+		cla, ins, p1, p2 byte
+		data             []byte
+		ne               uint
+		expected         []byte
+	}{
+		{0x00, 0xA4, 0x04, 0x00, nil, 0, []byte{0x00, 0xA4, 0x04, 0x00}},
+		{0x00, 0xB0, 0x00, 0x00, nil, 4, []byte{0x00, 0xB0, 0x00, 0x00, 0x04}},
+		{0x00, 0xB0, 0x00, 0x00, nil, 256, []byte{0x00, 0xB0, 0x00, 0x00, 0x00}},
+		{0x00, 0xB0, 0x01, 0x02, nil, 0x1234, []byte{0x00, 0xB0, 0x01, 0x02, 0x00, 0x12, 0x34}},
+		{0x00, 0xB0, 0x00, 0x00, nil, 65536, []byte{0x00, 0xB0, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{0x00, 0xA4, 0x08, 0x00, []byte{0x0F, 0x02}, 0, []byte{0x00, 0xA4, 0x08, 0x00, 0x02, 0x0F, 0x02}},
+		{0x00, 0xA4, 0x08, 0x00, []byte{0x0F, 0x02}, 4, []byte{0x00, 0xA4, 0x08, 0x00, 0x02, 0x0F, 0x02, 0x04}},
+		{0x00, 0xA4, 0x08, 0x00, []byte{0x0F, 0x02}, 256, []byte{0x00, 0xA4, 0x08, 0x00, 0x02, 0x0F, 0x02, 0x00}},
+		{0x00, 0xA4, 0x08, 0x00, []byte{0x0F, 0x02}, 0x1234, []byte{0x00, 0xA4, 0x08, 0x00, 0x00, 0x00, 0x02, 0x0F, 0x02, 0x12, 0x34}},
+		{0x80, 0x2A, 0x9E, 0x9A, []byte{0x01}, 0, []byte{0x80, 0x2A, 0x9E, 0x9A, 0x01, 0x01}},
+	}
+
+	for i, testCase := range testCases {
+		t.Run(
+			fmt.Sprintf("Case %d", i),
+			func(t *testing.T) {
+				res, err := buildAPDU(testCase.cla, testCase.ins, testCase.p1, testCase.p2, testCase.data, testCase.ne)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if !slices.Equal(res, testCase.expected) {
+					t.Errorf("Expected %X, but got %X", testCase.expected, res)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,201 @@
+package card
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/ubavic/bas-celik/v2/card/carderrors"
+	testhelpers "github.com/ubavic/bas-celik/v2/test_helpers"
+)
+
+func Test_buildAPDU_Iso7816Cases(t *testing.T) {
+	data255 := make([]byte, 255)
+	data256 := make([]byte, 256)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		ne       uint
+		expected []byte
+	}{
+		{name: "case 1", expected: []byte{0x00, 0xA4, 0x04, 0x00}},
+		{name: "case 2S", ne: 4, expected: []byte{0x00, 0xA4, 0x04, 0x00, 0x04}},
+		{name: "case 2S Le 256", ne: 256, expected: []byte{0x00, 0xA4, 0x04, 0x00, 0x00}},
+		{name: "case 2E", ne: 257, expected: []byte{0x00, 0xA4, 0x04, 0x00, 0x00, 0x01, 0x01}},
+		{name: "case 2E Le 65536", ne: 65536, expected: []byte{0x00, 0xA4, 0x04, 0x00, 0x00, 0x00, 0x00}},
+		{name: "case 3S", data: []byte{0x0F, 0x02}, expected: []byte{0x00, 0xA4, 0x04, 0x00, 0x02, 0x0F, 0x02}},
+		{name: "case 3S 255", data: data255, expected: append([]byte{0x00, 0xA4, 0x04, 0x00, 0xFF}, data255...)},
+		{name: "case 3E", data: data256, expected: append([]byte{0x00, 0xA4, 0x04, 0x00, 0x00, 0x01, 0x00}, data256...)},
+		{name: "case 4S", data: []byte{0x0F, 0x02}, ne: 4, expected: []byte{0x00, 0xA4, 0x04, 0x00, 0x02, 0x0F, 0x02, 0x04}},
+		{name: "case 4S Le 256", data: []byte{0x0F}, ne: 256, expected: []byte{0x00, 0xA4, 0x04, 0x00, 0x01, 0x0F, 0x00}},
+		{name: "case 4E long data", data: data256, ne: 4, expected: append(append([]byte{0x00, 0xA4, 0x04, 0x00, 0x00, 0x01, 0x00}, data256...), 0x00, 0x04)},
+		{name: "case 4E long Le", data: []byte{0x0F}, ne: 0x1000, expected: []byte{0x00, 0xA4, 0x04, 0x00, 0x00, 0x00, 0x01, 0x0F, 0x10, 0x00}},
+		{name: "case 4E Le 65536", data: []byte{0x0F}, ne: 65536, expected: []byte{0x00, 0xA4, 0x04, 0x00, 0x00, 0x00, 0x01, 0x0F, 0x00, 0x00}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apdu, err := buildAPDU(0x00, 0xA4, 0x04, 0x00, tt.data, tt.ne)
+			if err != nil {
+				t.Fatalf("buildAPDU() unexpected error: %v", err)
+			}
+
+			if !slices.Equal(apdu, tt.expected) {
+				t.Errorf("buildAPDU() = %X, expected %X", apdu, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_buildAPDU_Errors(t *testing.T) {
+	_, err := buildAPDU(0x00, 0xD6, 0x00, 0x00, make([]byte, 0x10000), 0)
+	if !errors.Is(err, carderrors.ErrInvalidLength) {
+		t.Errorf("expected ErrInvalidLength for long data, got %v", err)
+	}
+
+	_, err = buildAPDU(0x00, 0xB0, 0x00, 0x00, nil, 0x10001)
+	if !errors.Is(err, carderrors.ErrInvalidLength) {
+		t.Errorf("expected ErrInvalidLength for large Le, got %v", err)
+	}
+}
+
+func Test_sendAPDU(t *testing.T) {
+	t.Run("plain response", func(t *testing.T) {
+		cm := &testhelpers.CardMock{}
+		cm.On("Transmit", []byte{0x00, 0xB0, 0x00, 0x00, 0x02}).Return([]byte{0x01, 0x02, 0x90, 0x00}, nil).Once()
+
+		rsp, err := sendAPDU(cm, 0x00, 0xB0, 0x00, 0x00, nil, 2)
+		if err != nil {
+			t.Fatalf("sendAPDU() unexpected error: %v", err)
+		}
+
+		expected := []byte{0x01, 0x02, 0x90, 0x00}
+		if !slices.Equal(rsp.Bytes(), expected) {
+			t.Errorf("sendAPDU() = %X, expected %X", rsp.Bytes(), expected)
+		}
+
+		cm.AssertExpectations(t)
+	})
+
+	t.Run("get response", func(t *testing.T) {
+		cm := &testhelpers.CardMock{}
+		cm.On("Transmit", []byte{0x00, 0xB0, 0x00, 0x00, 0x00}).Return([]byte{0x01, 0x61, 0x02}, nil).Once()
+		cm.On("Transmit", []byte{0x00, 0xC0, 0x00, 0x00, 0x02}).Return([]byte{0x02, 0x03, 0x61, 0x00}, nil).Once()
+		cm.On("Transmit", []byte{0x00, 0xC0, 0x00, 0x00, 0x00}).Return([]byte{0x04, 0x90, 0x00}, nil).Once()
+
+		rsp, err := sendAPDU(cm, 0x00, 0xB0, 0x00, 0x00, nil, 256)
+		if err != nil {
+			t.Fatalf("sendAPDU() unexpected error: %v", err)
+		}
+
+		expected := []byte{0x01, 0x02, 0x03, 0x04, 0x90, 0x00}
+		if !slices.Equal(rsp.Bytes(), expected) {
+			t.Errorf("sendAPDU() = %X, expected %X", rsp.Bytes(), expected)
+		}
+
+		cm.AssertExpectations(t)
+	})
+
+	t.Run("wrong Le", func(t *testing.T) {
+		cm := &testhelpers.CardMock{}
+		cm.On("Transmit", []byte{0x00, 0xB0, 0x00, 0x00, 0x20}).Return([]byte{0x6C, 0x03}, nil).Once()
+		cm.On("Transmit", []byte{0x00, 0xB0, 0x00, 0x00, 0x03}).Return([]byte{0x01, 0x02, 0x03, 0x90, 0x00}, nil).Once()
+
+		rsp, err := sendAPDU(cm, 0x00, 0xB0, 0x00, 0x00, nil, 0x20)
+		if err != nil {
+			t.Fatalf("sendAPDU() unexpected error: %v", err)
+		}
+
+		expected := []byte{0x01, 0x02, 0x03, 0x90, 0x00}
+		if !slices.Equal(rsp.Bytes(), expected) {
+			t.Errorf("sendAPDU() = %X, expected %X", rsp.Bytes(), expected)
+		}
+
+		cm.AssertExpectations(t)
+	})
+
+	t.Run("transmit error", func(t *testing.T) {
+		cm := &testhelpers.CardMock{}
+		cm.On("Transmit", mock.Anything).Return([]byte{0x61, 0x02}, nil).Once()
+		cm.On("Transmit", mock.Anything).Return(nil, errors.New("removed")).Once()
+
+		_, err := sendAPDU(cm, 0x00, 0xB0, 0x00, 0x00, nil, 2)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		cm.AssertExpectations(t)
+	})
+
+	t.Run("build error", func(t *testing.T) {
+		cm := &testhelpers.CardMock{}
+
+		_, err := sendAPDU(cm, 0x00, 0xB0, 0x00, 0x00, nil, 0x10001)
+		if !errors.Is(err, carderrors.ErrInvalidLength) {
+			t.Errorf("expected ErrInvalidLength, got %v", err)
+		}
+
+		cm.AssertNotCalled(t, "Transmit", mock.Anything)
+	})
+}
+
+func Test_sendChainedAPDU(t *testing.T) {
+	data := make([]byte, 600)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	t.Run("chained", func(t *testing.T) {
+		cm := &testhelpers.CardMock{}
+		cm.On("Transmit", append([]byte{0x10, 0x2A, 0x9E, 0x9A, 0xFF}, data[:255]...)).Return([]byte{0x90, 0x00}, nil).Once()
+		cm.On("Transmit", append([]byte{0x10, 0x2A, 0x9E, 0x9A, 0xFF}, data[255:510]...)).Return([]byte{0x90, 0x00}, nil).Once()
+		cm.On("Transmit", append(append([]byte{0x00, 0x2A, 0x9E, 0x9A, 0x5A}, data[510:]...), 0x00)).Return([]byte{0xAB, 0x90, 0x00}, nil).Once()
+
+		rsp, err := sendChainedAPDU(cm, 0x00, 0x2A, 0x9E, 0x9A, data, 256)
+		if err != nil {
+			t.Fatalf("sendChainedAPDU() unexpected error: %v", err)
+		}
+
+		expected := []byte{0xAB, 0x90, 0x00}
+		if !slices.Equal(rsp.Bytes(), expected) {
+			t.Errorf("sendChainedAPDU() = %X, expected %X", rsp.Bytes(), expected)
+		}
+
+		cm.AssertExpectations(t)
+	})
+
+	t.Run("rejected part", func(t *testing.T) {
+		cm := &testhelpers.CardMock{}
+		cm.On("Transmit", mock.Anything).Return([]byte{0x68, 0x83}, nil).Once()
+
+		rsp, err := sendChainedAPDU(cm, 0x00, 0x2A, 0x9E, 0x9A, data, 0)
+		if err != nil {
+			t.Fatalf("sendChainedAPDU() unexpected error: %v", err)
+		}
+
+		if !slices.Equal(rsp.Bytes(), []byte{0x68, 0x83}) {
+			t.Errorf("sendChainedAPDU() = %X, expected 6883", rsp.Bytes())
+		}
+
+		cm.AssertExpectations(t)
+	})
+}
+
+func Test_read_WrongLe(t *testing.T) {
+	cm := &testhelpers.CardMock{}
+	cm.On("Transmit", []byte{0x00, 0xB0, 0x00, 0x04, 0x10}).Return([]byte{0x6C, 0x02}, nil).Once()
+	cm.On("Transmit", []byte{0x00, 0xB0, 0x00, 0x04, 0x02}).Return([]byte{0x0A, 0x0B, 0x90, 0x00}, nil).Once()
+
+	data, err := read(cm, 4, 0x10)
+	if err != nil {
+		t.Fatalf("read() unexpected error: %v", err)
+	}
+
+	if !slices.Equal(data, []byte{0x0A, 0x0B}) {
+		t.Errorf("read() = %X, expected 0A0B", data)
+	}
+
+	cm.AssertExpectations(t)
+}
@@ -172,7 +172,10 @@ func TestApolloSelectFile(t *testing.T) {
 			if err != nil {
 				t.Fatalf("selectFile() error = %v", err)
 			}
-			exp := buildAPDU(0x00, 0xA4, 0x08, 0x00, []byte{0xAA}, 2)
+			exp, err := buildAPDU(0x00, 0xA4, 0x08, 0x00, []byte{0xAA}, 2)
+			if err != nil {
+				t.Fatalf("buildAPDU() error = %v", err)
+			}
 			if len(cm.Calls) == 0 || !bytes.Equal(cm.Calls[0].Arguments.Get(0).([]byte), exp) {
 				t.Fatalf("selectFile APDU mismatch")
 			}
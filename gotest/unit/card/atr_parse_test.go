@@ -0,0 +1,177 @@
+package card
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ubavic/bas-celik/v2/card/carderrors"
+)
+
+func TestAtrParse_KnownAtrs(t *testing.T) {
+	tests := []struct {
+		name       string
+		atr        Atr
+		protocols  []int
+		historical int
+		hasTCK     bool
+	}{
+		{"apollo", APOLLO_ATR, []int{1}, 9, true},
+		{"gemalto 1", GEMALTO_ATR_1, []int{1}, 15, true},
+		{"gemalto 2", GEMALTO_ATR_2, []int{0, 1}, 9, true},
+		{"gemalto 3", GEMALTO_ATR_3, []int{0, 1}, 14, true},
+		{"gemalto 4", GEMALTO_ATR_4, []int{0, 1}, 14, true},
+		{"medical 1", MEDICAL_ATR_1, []int{1}, 4, true},
+		{"medical 2", MEDICAL_ATR_2, []int{0, 1}, 14, true},
+		{"vehicle 0", VEHICLE_ATR_0, []int{0, 1}, 11, true},
+		{"vehicle 2", VEHICLE_ATR_2, []int{1}, 13, true},
+		{"vehicle 3", VEHICLE_ATR_3, []int{1}, 13, true},
+		{"vehicle 4", VEHICLE_ATR_4, []int{1}, 13, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := tt.atr.Parse()
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			if info.TS != 0x3B || info.Convention != "direct" {
+				t.Errorf("expected direct convention, got %02x %s", info.TS, info.Convention)
+			}
+
+			if !slices.Equal(info.Protocols, tt.protocols) {
+				t.Errorf("expected protocols %v, got %v", tt.protocols, info.Protocols)
+			}
+
+			if len(info.HistoricalBytes) != tt.historical {
+				t.Errorf("expected %d historical bytes, got %d", tt.historical, len(info.HistoricalBytes))
+			}
+
+			if (info.TCK != nil) != tt.hasTCK {
+				t.Errorf("expected TCK present %t", tt.hasTCK)
+			}
+
+			if !info.TCKValid {
+				t.Errorf("expected valid TCK")
+			}
+		})
+	}
+}
+
+func TestAtrParse_Gemalto1(t *testing.T) {
+	info, err := GEMALTO_ATR_1.Parse()
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	if len(info.InterfaceBytes) != 3 {
+		t.Fatalf("expected 3 groups of interface bytes, got %d", len(info.InterfaceBytes))
+	}
+
+	first := info.InterfaceBytes[0]
+	if first.TA == nil || *first.TA != 0x94 || first.TB == nil || first.TC == nil || first.TD == nil || *first.TD != 0x81 {
+		t.Errorf("unexpected first group %+v", first)
+	}
+
+	third := info.InterfaceBytes[2]
+	if third.TA == nil || *third.TA != 0x80 || third.TB == nil || *third.TB != 0x43 || third.TC != nil || third.TD != nil {
+		t.Errorf("unexpected third group %+v", third)
+	}
+
+	if *info.TCK != 0x79 {
+		t.Errorf("expected TCK 79, got %02x", *info.TCK)
+	}
+
+	// 80 | 31 80 | 65 B0 85 02 01 F3 | 12 0F FF | 82 90 00
+	tags := []byte{}
+	for _, field := range info.Historical {
+		tags = append(tags, field.Tag)
+	}
+
+	if !slices.Equal(tags, []byte{0x3, 0x6, 0x1, 0x8}) {
+		t.Errorf("unexpected COMPACT-TLV tags %v", tags)
+	}
+
+	description := info.String()
+	for _, expected := range []string{"TS: 3b (direct convention)", "TA1: 94", "TD2: 31", "Protocols: T=1", "TCK: 79 (valid)"} {
+		if !strings.Contains(description, expected) {
+			t.Errorf("description doesn't contain %q:\n%s", expected, description)
+		}
+	}
+}
+
+func TestAtrParse_Description(t *testing.T) {
+	info, err := GEMALTO_ATR_3.Parse()
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	if !strings.Contains(info.String(), `"SCE 8.0-C1V0"`) {
+		t.Errorf("expected historical text in description:\n%s", info.String())
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+
+	if !strings.Contains(string(data), `"historicalBytes":"534345203`) {
+		t.Errorf("expected hex historical bytes in JSON, got %s", data)
+	}
+}
+
+func TestAtrParse_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		atr      Atr
+		expected error
+	}{
+		{"empty", Atr{}, carderrors.ErrInvalidLength},
+		{"bad TS", Atr{0x3A, 0x00}, carderrors.ErrInvalidFormat},
+		{"missing interface byte", Atr{0x3B, 0x90, 0x11}, carderrors.ErrInvalidLength},
+		{"missing historical bytes", Atr{0x3B, 0x03, 0x01}, carderrors.ErrInvalidLength},
+		{"missing TCK", Atr{0x3B, 0x80, 0x01}, carderrors.ErrInvalidLength},
+		{"extra bytes", Atr{0x3B, 0x00, 0x01}, carderrors.ErrInvalidLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.atr.Parse()
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestAtrParse_InvalidTCK(t *testing.T) {
+	atr := slices.Clone(GEMALTO_ATR_3)
+	atr[len(atr)-1] ^= 0xFF
+
+	info, err := atr.Parse()
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	if info.TCKValid {
+		t.Error("expected invalid TCK")
+	}
+
+	if !strings.Contains(info.String(), "(invalid)") {
+		t.Errorf("expected invalid TCK in description:\n%s", info.String())
+	}
+}
+
+func TestAtrParse_NoProtocol(t *testing.T) {
+	info, err := Atr{0x3F, 0x11, 0x96, 0x41}.Parse()
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	if info.Convention != "inverse" || !slices.Equal(info.Protocols, []int{0}) || info.TCK != nil {
+		t.Errorf("unexpected info %+v", info)
+	}
+}
@@ -0,0 +1,206 @@
+package card
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ebfe/scard"
+	"github.com/stretchr/testify/mock"
+	"github.com/ubavic/bas-celik/v2/card/carderrors"
+	testhelpers "github.com/ubavic/bas-celik/v2/test_helpers"
+)
+
+// GEMALTO_ATR_3 with a new card revision ("C3") and a matching TCK
+var sceC3Atr = Atr{
+	0x3B, 0x9E, 0x96, 0x80, 0x31, 0xFE, 0x45, 0x53,
+	0x43, 0x45, 0x20, 0x38, 0x2E, 0x30, 0x2D, 0x43,
+	0x33, 0x56, 0x30, 0x0D, 0x0A, 0x6D,
+}
+
+// VEHICLE_ATR_3 with a different version byte
+var vehicleVariantAtr = Atr{
+	0x3B, 0x9D, 0x13, 0x81, 0x31, 0x60, 0x37, 0x80,
+	0x31, 0xC0, 0x69, 0x4D, 0x54, 0x43, 0x4F, 0x53,
+	0x73, 0x02, 0x06, 0x04, 0x44,
+}
+
+func TestParseAtrPattern(t *testing.T) {
+	pattern, err := ParseAtrPattern("3B9E9680 31FE4553 4345 20382E30 2D43 31 56 ..")
+	if err != nil {
+		t.Fatalf("ParseAtrPattern() unexpected error: %v", err)
+	}
+
+	if pattern.String() != "3b9e968031fe4553434520382e302d433156.." {
+		t.Errorf("unexpected pattern %s", pattern)
+	}
+
+	if !GEMALTO_ATR_3[:19].Matches(pattern) || GEMALTO_ATR_4[:19].Matches(pattern) {
+		t.Error("expected pattern to match only the first revision")
+	}
+
+	if GEMALTO_ATR_3.Matches(pattern) {
+		t.Error("expected pattern not to match a longer ATR")
+	}
+
+	nibble := MustParseAtrPattern("3b9.")
+	if nibble.String() != "3b9." {
+		t.Errorf("unexpected pattern %s", nibble)
+	}
+
+	if !(Atr{0x3B, 0x9E}).Matches(nibble) || (Atr{0x3B, 0x8E}).Matches(nibble) {
+		t.Error("expected nibble wildcard to match only the low nibble")
+	}
+}
+
+func TestParseAtrPattern_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		expected error
+	}{
+		{"empty", " ", carderrors.ErrInvalidLength},
+		{"odd length", "3B9", carderrors.ErrInvalidLength},
+		{"invalid digit", "3B 9G", carderrors.ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseAtrPattern(tt.pattern)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestMustParseAtrPattern_Panics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+
+	MustParseAtrPattern("3B 9X")
+}
+
+func TestAtrMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		atr      Atr
+		pattern  AtrPattern
+		expected bool
+	}{
+		{"gemalto 3", GEMALTO_ATR_3, GEMALTO_ATR_PATTERN, true},
+		{"gemalto 4", GEMALTO_ATR_4, GEMALTO_ATR_PATTERN, true},
+		{"gemalto C3", sceC3Atr, GEMALTO_ATR_PATTERN, true},
+		{"gemalto 1", GEMALTO_ATR_1, GEMALTO_ATR_PATTERN, false},
+		{"medical 2 with gemalto", MEDICAL_ATR_2, GEMALTO_ATR_PATTERN, false},
+		{"medical 2", MEDICAL_ATR_2, MEDICAL_ATR_PATTERN, true},
+		{"medical with gemalto 3", GEMALTO_ATR_3, MEDICAL_ATR_PATTERN, true},
+		{"vehicle 2", VEHICLE_ATR_2, VEHICLE_ATR_PATTERN, true},
+		{"vehicle 3", VEHICLE_ATR_3, VEHICLE_ATR_PATTERN, true},
+		{"vehicle 4", VEHICLE_ATR_4, VEHICLE_ATR_PATTERN, true},
+		{"vehicle 0", VEHICLE_ATR_0, VEHICLE_ATR_PATTERN, false},
+		{"shorter atr", VEHICLE_ATR_2[:20], VEHICLE_ATR_PATTERN, false},
+		{"longer atr", append(slices.Clone(VEHICLE_ATR_2), 0x00), VEHICLE_ATR_PATTERN, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.atr.Matches(tt.pattern); got != tt.expected {
+				t.Errorf("%s.Matches(%s) = %t, expected %t", tt.atr, tt.pattern, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDriversByAtr_Patterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		atr      Atr
+		expected []CardDocumentType
+	}{
+		{"exact match has precedence", MEDICAL_ATR_2, []CardDocumentType{MedicalDocumentCardType}},
+		{"sce family", sceC3Atr, []CardDocumentType{GemaltoIDDocumentCardType, MedicalDocumentCardType, VehicleDocumentCardType}},
+		{"vehicle family", vehicleVariantAtr, []CardDocumentType{VehicleDocumentCardType}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectCardDocumentByAtr(tt.atr)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("DetectCardDocumentByAtr(%s) = %v, expected %v", tt.atr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetectCardDocument_Pattern(t *testing.T) {
+	cm := &testhelpers.CardMock{}
+	cm.On("Status").Return(&scard.CardStatus{Atr: sceC3Atr}, nil).Once()
+	// Gemalto InitCard fails for all three applets
+	cm.On("Transmit", mock.Anything).Return([]byte{0x6A, 0x82}, nil).Times(3)
+	// Medical Test fails on selecting the document file
+	cm.On("Transmit", mock.Anything).Return([]byte{0x6A, 0x82}, nil).Times(2)
+	// Vehicle InitCard succeeds
+	cm.On("Transmit", mock.Anything).Return([]byte{0x90, 0x00}, nil).Times(3)
+
+	doc, err := DetectCardDocument(cm)
+	if err != nil {
+		t.Fatalf("DetectCardDocument() unexpected error: %v", err)
+	}
+
+	if _, ok := doc.(*VehicleCard); !ok {
+		t.Fatalf("expected Vehicle card, got %T", doc)
+	}
+
+	if !doc.Atr().Is(sceC3Atr) {
+		t.Errorf("expected ATR %s, got %s", sceC3Atr, doc.Atr())
+	}
+
+	cm.AssertExpectations(t)
+}
+
+func TestDetectCardDocument_AmbiguousWithoutProbe(t *testing.T) {
+	saved := Drivers()
+	t.Cleanup(func() { drivers = saved })
+
+	pattern := MustParseAtrPattern("3B 01 ..")
+
+	RegisterDriver(Driver{
+		Type:     GemaltoIDDocumentCardType,
+		Name:     "Gemalto without probe",
+		Patterns: []AtrPattern{pattern},
+		Priority: 1,
+		New: func(atr Atr, smartCard Card) CardDocument {
+			return &Gemalto{atr: atr, smartCard: smartCard}
+		},
+	})
+
+	RegisterDriver(Driver{
+		Type:     VehicleDocumentCardType,
+		Name:     "Vehicle without probe",
+		Patterns: []AtrPattern{pattern},
+		Priority: 2,
+		New: func(atr Atr, smartCard Card) CardDocument {
+			return &VehicleCard{atr: atr, smartCard: smartCard}
+		},
+	})
+
+	cm := &testhelpers.CardMock{}
+	cm.On("Status").Return(&scard.CardStatus{Atr: []byte{0x3B, 0x01, 0x42}}, nil).Once()
+	cm.On("Transmit", mock.Anything).Return([]byte{0x6A, 0x82}, nil).Times(3)
+	cm.On("Transmit", mock.Anything).Return([]byte{0x90, 0x00}, nil).Times(3)
+
+	doc, err := DetectCardDocument(cm)
+	if err != nil {
+		t.Fatalf("DetectCardDocument() unexpected error: %v", err)
+	}
+
+	if _, ok := doc.(*VehicleCard); !ok {
+		t.Fatalf("expected Vehicle card, got %T", doc)
+	}
+
+	cm.AssertExpectations(t)
+}
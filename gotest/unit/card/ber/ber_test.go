@@ -3,9 +3,13 @@ package ber
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
 	"testing"
 
-	carderrors "github.com/ubavic/bas-celik/v2/card/cardErrors"
+	carderrors "github.com/ubavic/bas-celik/v2/card/carderrors"
 )
 
 func Test_parseBerLength(t *testing.T) {
@@ -92,7 +96,7 @@ func Test_parseBerLength(t *testing.T) {
 func Test_parseBerTag(t *testing.T) {
 	testCases := []struct {
 		data                []byte
-		expectedTag         uint32
+		expectedTag         uint64
 		expectedPrimitive   bool
 		expectedParsedBytes uint32
 		expectedError       error
@@ -117,25 +121,54 @@ func Test_parseBerTag(t *testing.T) {
 		},
 		{
 			data:                []byte{0b10111111, 0b00101111},
-			expectedTag:         uint32(binary.BigEndian.Uint16([]byte{0b10111111, 0b00101111})),
+			expectedTag:         uint64(binary.BigEndian.Uint16([]byte{0b10111111, 0b00101111})),
 			expectedPrimitive:   false,
 			expectedParsedBytes: 2,
 			expectedError:       nil,
 		},
 		{
 			data:                []byte{0b10111111, 0b10101111},
-			expectedTag:         uint32(binary.BigEndian.Uint16([]byte{0b10111111, 0b10101111})),
+			expectedTag:         uint64(binary.BigEndian.Uint16([]byte{0b10111111, 0b10101111})),
 			expectedPrimitive:   false,
 			expectedParsedBytes: 2,
 			expectedError:       carderrors.ErrInvalidLength,
 		},
 		{
-			data:                []byte{0b10111111, 0b10101111, 0b011010101},
-			expectedTag:         uint32(binary.BigEndian.Uint32([]byte{0, 0b10111111, 0b10101111, 0b011010101})),
+			data:          []byte{0b10111111, 0b10101111, 0b011010101},
+			expectedError: carderrors.ErrInvalidLength,
+		},
+		{
+			data:                []byte{0b10111111, 0b10101111, 0b01010101, 0x01},
+			expectedTag:         uint64(binary.BigEndian.Uint32([]byte{0, 0b10111111, 0b10101111, 0b01010101})),
 			expectedPrimitive:   false,
 			expectedParsedBytes: 3,
 			expectedError:       nil,
 		},
+		{
+			data:                []byte{0b10011111, 0b10101111, 0b11010101, 0b00000001},
+			expectedTag:         uint64(binary.BigEndian.Uint32([]byte{0b10011111, 0b10101111, 0b11010101, 0b00000001})),
+			expectedPrimitive:   true,
+			expectedParsedBytes: 4,
+			expectedError:       nil,
+		},
+		{
+			data:                []byte{0b10011111, 0b10101111, 0b11010101, 0b10000001, 0b00000001},
+			expectedTag:         0x9FAFD58101,
+			expectedPrimitive:   true,
+			expectedParsedBytes: 5,
+			expectedError:       nil,
+		},
+		{
+			data:                []byte{0x7F, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x07},
+			expectedTag:         0x7F81828384858607,
+			expectedPrimitive:   false,
+			expectedParsedBytes: 8,
+			expectedError:       nil,
+		},
+		{
+			data:          []byte{0x7F, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87, 0x08},
+			expectedError: carderrors.ErrInvalidFormat,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -194,15 +227,16 @@ func Test_parseBERLayer(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			prim, cons, err := parseBERLayer(tt.data)
+			p := parser{data: tt.data}
+			nodes, next, err := p.parseBERLayer(0, len(tt.data), 1, false)
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("parseBERLayer() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if tt.wantErr {
 				return
 			}
-			if len(prim) != 1 || len(cons) != 0 || !bytes.Equal(prim[0x01], []byte{0xAA}) {
-				t.Fatalf("unexpected maps prim=%v cons=%v", prim, cons)
+			if len(nodes) != 1 || next != len(tt.data) || nodes[0].tag != 0x01 || !nodes[0].primitive || !bytes.Equal(nodes[0].data, []byte{0xAA}) {
+				t.Fatalf("unexpected nodes %+v", nodes)
 			}
 		})
 	}
@@ -219,12 +253,12 @@ func TestBERAccess(t *testing.T) {
 	}
 	tests := []struct {
 		name    string
-		address []uint32
+		address []uint64
 		want    []byte
 		wantErr bool
 	}{
-		{"found", []uint32{1, 2}, []byte("val"), false},
-		{"missing", []uint32{3}, nil, true},
+		{"found", []uint64{1, 2}, []byte("val"), false},
+		{"missing", []uint64{3}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -248,7 +282,7 @@ func TestBERAdd(t *testing.T) {
 		tree       BER
 		newNode    BER
 		wantErr    bool
-		checkChild uint32
+		checkChild uint64
 	}{
 		{"into primitive", BER{primitive: true}, BER{tag: 1}, true, 0},
 		{"new child", BER{}, BER{tag: 1, primitive: true, data: []byte{0x01}}, false, 1},
@@ -316,12 +350,12 @@ func TestAssignFromAndString(t *testing.T) {
 
 	tests := []struct {
 		name     string
-		address  []uint32
+		address  []uint64
 		start    string
 		expected string
 	}{
-		{"assign success", []uint32{1}, "", "abc"},
-		{"assign missing", []uint32{2}, "keep", "keep"},
+		{"assign success", []uint64{1}, "", "abc"},
+		{"assign missing", []uint64{2}, "keep", "keep"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -337,3 +371,526 @@ func TestAssignFromAndString(t *testing.T) {
 		t.Fatalf("String() = %q, want %q", s, "0:\n  1: abc")
 	}
 }
+
+func TestBERWalk(t *testing.T) {
+	tree := BER{tag: 0, children: []BER{
+		{tag: 0x72, children: []BER{{tag: 0x98, primitive: true, data: []byte("M1")}}},
+		{tag: 0x71, children: []BER{
+			{tag: 0xA1, children: []BER{{tag: 0x83, primitive: true, data: []byte("owner")}}},
+			{tag: 0x81, primitive: true, data: []byte("BG123")},
+			{tag: 0xA1, children: []BER{
+				{tag: 0x83, primitive: true, data: []byte("user")},
+				{tag: 0x83, primitive: true, data: []byte("other user")},
+			}},
+		}},
+		{tag: 0x01, primitive: true, data: []byte{}},
+	}}
+
+	type node struct {
+		address []uint64
+		indices []int
+		data    string
+	}
+
+	nodes := []node{}
+	tree.Walk(func(address []uint64, indices []int, data []byte) {
+		nodes = append(nodes, node{address, indices, string(data)})
+	})
+
+	expected := []node{
+		{[]uint64{0x01}, []int{0}, ""},
+		{[]uint64{0x71, 0x81}, []int{0, 0}, "BG123"},
+		{[]uint64{0x71, 0xA1, 0x83}, []int{0, 0, 0}, "owner"},
+		{[]uint64{0x71, 0xA1, 0x83}, []int{0, 1, 0}, "user"},
+		{[]uint64{0x71, 0xA1, 0x83}, []int{0, 1, 1}, "other user"},
+		{[]uint64{0x72, 0x98}, []int{0, 0}, "M1"},
+	}
+
+	if len(nodes) != len(expected) {
+		t.Fatalf("Walk() visited %+v, want %+v", nodes, expected)
+	}
+
+	for i := range expected {
+		if !slices.Equal(nodes[i].address, expected[i].address) || !slices.Equal(nodes[i].indices, expected[i].indices) || nodes[i].data != expected[i].data {
+			t.Errorf("node %d = %+v, want %+v", i, nodes[i], expected[i])
+		}
+	}
+
+	// Order of children is not changed
+	if tree.children[0].tag != 0x72 {
+		t.Errorf("Walk() modified the tree: %+v", tree.children)
+	}
+}
+
+func TestEncodeTag(t *testing.T) {
+	tests := []struct {
+		tag      uint64
+		expected []byte
+		wantErr  bool
+	}{
+		{tag: 0x01, expected: []byte{0x01}},
+		{tag: 0x71, expected: []byte{0x71}},
+		{tag: 0x5F20, expected: []byte{0x5F, 0x20}},
+		{tag: 0x7F49, expected: []byte{0x7F, 0x49}},
+		{tag: 0x9F8101, expected: []byte{0x9F, 0x81, 0x01}},
+		{tag: 0x7F818101, expected: []byte{0x7F, 0x81, 0x81, 0x01}},
+		{tag: 0x9F8181818101, expected: []byte{0x9F, 0x81, 0x81, 0x81, 0x81, 0x01}},
+		{tag: 0x7F81828384858607, expected: []byte{0x7F, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x07}},
+		{tag: 0x00, wantErr: true},
+		{tag: 0x1F, wantErr: true},
+		{tag: 0x5F81, wantErr: true},
+		{tag: 0x4F20, wantErr: true},
+		{tag: 0x9F0101, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := EncodeTag(tt.tag)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("EncodeTag(%X) error = %v, wantErr %v", tt.tag, err, tt.wantErr)
+		}
+
+		if tt.wantErr {
+			if !errors.Is(err, carderrors.ErrInvalidFormat) {
+				t.Errorf("EncodeTag(%X) error = %v, expected %v", tt.tag, err, carderrors.ErrInvalidFormat)
+			}
+			continue
+		}
+
+		if !bytes.Equal(got, tt.expected) {
+			t.Errorf("EncodeTag(%X) = %X, expected %X", tt.tag, got, tt.expected)
+		}
+
+		tag, _, parsed, err := ParseTag(got)
+		if err != nil || tag != tt.tag || parsed != uint32(len(got)) {
+			t.Errorf("ParseTag(%X) = %X, %d, %v, expected %X", got, tag, parsed, err, tt.tag)
+		}
+	}
+}
+
+func TestEncodeLength(t *testing.T) {
+	tests := []struct {
+		length   uint32
+		expected []byte
+	}{
+		{0x00, []byte{0x00}},
+		{0x7F, []byte{0x7F}},
+		{0x80, []byte{0x81, 0x80}},
+		{0xFF, []byte{0x81, 0xFF}},
+		{0x100, []byte{0x82, 0x01, 0x00}},
+		{0xFFFF, []byte{0x82, 0xFF, 0xFF}},
+		{0x10000, []byte{0x83, 0x01, 0x00, 0x00}},
+		{0xFFFFFF, []byte{0x83, 0xFF, 0xFF, 0xFF}},
+		{0x1000000, []byte{0x84, 0x01, 0x00, 0x00, 0x00}},
+		{0xFFFFFFFF, []byte{0x84, 0xFF, 0xFF, 0xFF, 0xFF}},
+	}
+
+	for _, tt := range tests {
+		got := EncodeLength(tt.length)
+		if !bytes.Equal(got, tt.expected) {
+			t.Errorf("EncodeLength(%X) = %X, expected %X", tt.length, got, tt.expected)
+		}
+
+		length, parsed, err := ParseLength(got)
+		if err != nil || length != tt.length || parsed != uint32(len(got)) {
+			t.Errorf("ParseLength(%X) = %X, %d, %v, expected %X", got, length, parsed, err, tt.length)
+		}
+	}
+}
+
+func TestNewPrimitiveAndNewConstructed(t *testing.T) {
+	if _, err := NewPrimitive(0x71, []byte{0x01}); err == nil {
+		t.Error("NewPrimitive() expected error for constructed tag")
+	}
+
+	if _, err := NewPrimitive(0x1F, []byte{0x01}); err == nil {
+		t.Error("NewPrimitive() expected error for invalid tag")
+	}
+
+	primitive, err := NewPrimitive(0x81, []byte{0x01})
+	if err != nil {
+		t.Fatalf("NewPrimitive() unexpected error: %v", err)
+	}
+
+	if _, err := NewConstructed(0x81, primitive); err == nil {
+		t.Error("NewConstructed() expected error for primitive tag")
+	}
+
+	if _, err := NewConstructed(0x71, primitive, primitive); err == nil {
+		t.Error("NewConstructed() expected error for duplicate tags")
+	}
+
+	root, err := NewConstructed(0, primitive)
+	if err != nil {
+		t.Fatalf("NewConstructed() unexpected error: %v", err)
+	}
+
+	if _, err := NewConstructed(0x71, root); err == nil {
+		t.Error("NewConstructed() expected error for root child")
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	mustPrimitive := func(tag uint64, data []byte) BER {
+		node, err := NewPrimitive(tag, data)
+		if err != nil {
+			t.Fatalf("NewPrimitive(%X) unexpected error: %v", tag, err)
+		}
+		return node
+	}
+
+	mustConstructed := func(tag uint64, children ...BER) BER {
+		node, err := NewConstructed(tag, children...)
+		if err != nil {
+			t.Fatalf("NewConstructed(%X) unexpected error: %v", tag, err)
+		}
+		return node
+	}
+
+	tree := mustConstructed(0,
+		mustConstructed(0x71,
+			mustPrimitive(0x81, []byte("BG123")),
+			mustConstructed(0xA1, mustPrimitive(0x83, []byte("owner"))),
+			mustPrimitive(0x5F20, bytes.Repeat([]byte{0xAB}, 0x80)),
+		),
+		mustConstructed(0x72, mustPrimitive(0x98, []byte("M1"))),
+		mustPrimitive(0x9F8101, bytes.Repeat([]byte{0xCD}, 0x100)),
+		mustPrimitive(0x01, []byte{}),
+	)
+
+	encoded, err := tree.Encode()
+	if err != nil {
+		t.Fatalf("Encode() unexpected error: %v", err)
+	}
+
+	// Children are encoded in order
+	if !bytes.HasPrefix(encoded, []byte{0x71, 0x81, 0x94, 0x81, 0x05}) {
+		t.Errorf("Encode() = %X, unexpected prefix", encoded[:8])
+	}
+
+	parsed, err := ParseBER(encoded)
+	if err != nil {
+		t.Fatalf("ParseBER() unexpected error: %v", err)
+	}
+
+	type node struct {
+		address []uint64
+		data    []byte
+	}
+
+	collect := func(tree BER) []node {
+		nodes := []node{}
+		tree.Walk(func(address []uint64, _ []int, data []byte) {
+			nodes = append(nodes, node{address, data})
+		})
+		return nodes
+	}
+
+	expected := collect(tree)
+	got := collect(*parsed)
+	if len(got) != len(expected) {
+		t.Fatalf("ParseBER(Encode()) = %+v, expected %+v", got, expected)
+	}
+
+	for i := range expected {
+		if !slices.Equal(got[i].address, expected[i].address) || !bytes.Equal(got[i].data, expected[i].data) {
+			t.Errorf("node %d = %+v, expected %+v", i, got[i], expected[i])
+		}
+	}
+
+	reencoded, err := parsed.children[0].Encode()
+	if err != nil {
+		t.Fatalf("Encode() unexpected error: %v", err)
+	}
+
+	if _, err := ParseBER(reencoded); err != nil {
+		t.Errorf("ParseBER() unexpected error for encoded subtree: %v", err)
+	}
+}
+
+func TestParseBER_OrderAndRepeatedTags(t *testing.T) {
+	data := []byte{
+		0x72, 0x03, 0x98, 0x01, 0x4D,
+		0x81, 0x01, 0x41,
+		0x81, 0x01, 0x42,
+	}
+
+	tree, err := ParseBER(data)
+	if err != nil {
+		t.Fatalf("ParseBER() unexpected error: %v", err)
+	}
+
+	tags := []uint64{}
+	for _, child := range tree.children {
+		tags = append(tags, child.tag)
+	}
+
+	if !slices.Equal(tags, []uint64{0x72, 0x81, 0x81}) {
+		t.Fatalf("ParseBER() children tags = %X, want [72 81 81]", tags)
+	}
+
+	if !bytes.Equal(tree.children[2].data, []byte("B")) {
+		t.Errorf("ParseBER() repeated tag data = %s, want B", tree.children[2].data)
+	}
+}
+
+func TestBERQuery(t *testing.T) {
+	tree := BER{tag: 0, children: []BER{
+		{tag: 0x71, children: []BER{
+			{tag: 0xA5, children: []BER{{tag: 0x9E, primitive: true, data: []byte("E1")}}},
+			{tag: 0xA5, children: []BER{{tag: 0x9E, primitive: true, data: []byte("E2")}}},
+			{tag: 0x81, primitive: true, data: []byte("BG123")},
+		}},
+		{tag: 0x72, children: []BER{
+			{tag: 0xA5, children: []BER{{tag: 0x9E, primitive: true, data: []byte("E3")}}},
+		}},
+		{tag: 0x9F33, primitive: true, data: []byte("SRB")},
+	}}
+
+	tests := []struct {
+		path     string
+		expected []string
+		wantErr  bool
+	}{
+		{path: "71/81", expected: []string{"BG123"}},
+		{path: "/71/81/", expected: []string{"BG123"}},
+		{path: "9f33", expected: []string{"SRB"}},
+		{path: "71/A5/9E", expected: []string{"E1", "E2"}},
+		{path: "71/A5[1]/9E", expected: []string{"E2"}},
+		{path: "71/A5[2]/9E", expected: []string{}},
+		{path: "*/A5/9E", expected: []string{"E1", "E2", "E3"}},
+		{path: "*/A5[0]/9E", expected: []string{"E1", "E3"}},
+		{path: "73/81", expected: []string{}},
+		{path: "71/81/82", expected: []string{}},
+		{path: "71/XY", wantErr: true},
+		{path: "71/A5[", wantErr: true},
+		{path: "71/A5[-1]", wantErr: true},
+		{path: "0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			nodes, err := tree.Query(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Query() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, carderrors.ErrInvalidFormat) {
+					t.Errorf("Query() error = %v, expected %v", err, carderrors.ErrInvalidFormat)
+				}
+				return
+			}
+
+			got := []string{}
+			for _, node := range nodes {
+				got = append(got, string(node.Data()))
+			}
+
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Query() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	nodes, err := tree.Query("")
+	if err != nil || len(nodes) != 1 || nodes[0].Tag() != 0 || len(nodes[0].children) != 3 {
+		t.Errorf("Query() with empty path = %+v, %v, expected the tree", nodes, err)
+	}
+}
+
+func TestParseBER_LongTag(t *testing.T) {
+	data := []byte{0x7F, 0x81, 0x81, 0x81, 0x01, 0x09, 0x9F, 0x81, 0x82, 0x83, 0x84, 0x05, 0x02, 'O', 'K'}
+
+	tree, err := ParseBER(data)
+	if err != nil {
+		t.Fatalf("ParseBER() unexpected error: %v", err)
+	}
+
+	nodes, err := tree.Query("7F81818101/9F8182838405")
+	if err != nil || len(nodes) != 1 || string(nodes[0].Data()) != "OK" {
+		t.Fatalf("Query() = %+v, %v, expected the node with OK", nodes, err)
+	}
+
+	if nodes[0].Tag() != 0x9F8182838405 {
+		t.Errorf("Tag() = %X, expected 9F8182838405", nodes[0].Tag())
+	}
+
+	encoded, err := tree.Encode()
+	if err != nil || !bytes.Equal(encoded, data) {
+		t.Errorf("Encode() = %X, %v, expected %X", encoded, err, data)
+	}
+
+	got, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatalf("MarshalJSON() unexpected error: %v", err)
+	}
+
+	if !strings.Contains(string(got), `{"tag":"7F81818101","length":9,`) {
+		t.Errorf("MarshalJSON() = %s, expected the long tag", got)
+	}
+}
+
+func TestBERMarshalJSON(t *testing.T) {
+	tree, err := ParseBER([]byte{
+		0x71, 0x0F,
+		0x81, 0x05, 'B', 'G', '1', '2', '3',
+		0x5F, 0x20, 0x02, 0x00, 0xFF,
+		0x9F, 0x33, 0x00,
+	})
+	if err != nil {
+		t.Fatalf("ParseBER() unexpected error: %v", err)
+	}
+
+	got, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatalf("MarshalJSON() unexpected error: %v", err)
+	}
+
+	expected := `{"length":17,"children":[{"tag":"71","length":15,"children":[` +
+		`{"tag":"81","length":5,"data":"4247313233","text":"BG123"},` +
+		`{"tag":"5F20","length":2,"data":"00FF"},` +
+		`{"tag":"9F33","length":0}]}]}`
+
+	if string(got) != expected {
+		t.Errorf("MarshalJSON() = %s\nwant %s", got, expected)
+	}
+}
+
+func TestParseBER_IndefiniteLength(t *testing.T) {
+	data := []byte{
+		0x71, 0x80,
+		0x81, 0x02, 'B', 'G',
+		0xA1, 0x80, 0x83, 0x01, 'O', 0x00, 0x00,
+		0xA5, 0x00,
+		0x00, 0x00,
+		0x72, 0x03, 0x98, 0x01, 'M',
+	}
+
+	tree, err := ParseBER(data)
+	if err != nil {
+		t.Fatalf("ParseBER() unexpected error: %v", err)
+	}
+
+	for _, tt := range []struct {
+		address  []uint64
+		expected string
+	}{
+		{[]uint64{0x71, 0x81}, "BG"},
+		{[]uint64{0x71, 0xA1, 0x83}, "O"},
+		{[]uint64{0x72, 0x98}, "M"},
+	} {
+		got, err := tree.access(tt.address...)
+		if err != nil || string(got) != tt.expected {
+			t.Errorf("access(%X) = %s, %v, want %s", tt.address, got, err, tt.expected)
+		}
+	}
+
+	// Empty constructed record
+	nodes, err := tree.Query("71/A5")
+	if err != nil || len(nodes) != 1 || nodes[0].primitive || len(nodes[0].children) != 0 {
+		t.Errorf("Query(71/A5) = %+v, %v, want empty constructed node", nodes, err)
+	}
+}
+
+func TestParseBER_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		limits   Limits
+		offset   int
+		tag      uint64
+		expected error
+	}{
+		{
+			name:     "truncated value",
+			data:     []byte{0x81, 0x01, 0xAA, 0x82, 0x05, 0xBB},
+			limits:   DefaultLimits,
+			offset:   3,
+			tag:      0x82,
+			expected: carderrors.ErrInvalidLength,
+		},
+		{
+			name:     "long length form beyond data",
+			data:     []byte{0x71, 0x05, 0x81, 0x83, 0x00, 0x10, 0x00},
+			limits:   DefaultLimits,
+			offset:   2,
+			tag:      0x81,
+			expected: carderrors.ErrInvalidLength,
+		},
+		{
+			name:     "truncated length",
+			data:     []byte{0x81, 0x82, 0x01},
+			limits:   DefaultLimits,
+			offset:   0,
+			tag:      0x81,
+			expected: carderrors.ErrInvalidLength,
+		},
+		{
+			name:     "indefinite primitive",
+			data:     []byte{0x81, 0x80, 0xAA, 0x00, 0x00},
+			limits:   DefaultLimits,
+			offset:   0,
+			tag:      0x81,
+			expected: carderrors.ErrInvalidFormat,
+		},
+		{
+			name:     "missing end-of-contents",
+			data:     []byte{0x71, 0x80, 0x81, 0x01, 0xAA},
+			limits:   DefaultLimits,
+			offset:   5,
+			expected: carderrors.ErrInvalidLength,
+		},
+		{
+			name:     "tag longer than eight bytes",
+			data:     []byte{0x71, 0x0B, 0x9F, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x01, 0x00, 0x00},
+			limits:   DefaultLimits,
+			offset:   2,
+			expected: carderrors.ErrInvalidFormat,
+		},
+		{
+			name:     "depth",
+			data:     []byte{0x71, 0x06, 0x72, 0x04, 0x73, 0x02, 0x81, 0x00},
+			limits:   Limits{MaxDepth: 3},
+			offset:   6,
+			expected: ErrLimitExceeded,
+		},
+		{
+			name:     "nodes",
+			data:     []byte{0x81, 0x00, 0x82, 0x00, 0x83, 0x00},
+			limits:   Limits{MaxNodes: 2},
+			offset:   4,
+			tag:      0x83,
+			expected: ErrLimitExceeded,
+		},
+		{
+			name:     "length",
+			data:     []byte{0x81, 0x01, 0xAA},
+			limits:   Limits{MaxLength: 2},
+			offset:   0,
+			expected: ErrLimitExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBERWithLimits(tt.data, tt.limits)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("ParseBERWithLimits() error = %v, expected %v", err, tt.expected)
+			}
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("ParseBERWithLimits() error = %T, expected *ParseError", err)
+			}
+
+			if parseErr.Offset != tt.offset || parseErr.Tag != tt.tag {
+				t.Errorf("ParseError offset = %d, tag = %X, expected %d, %X", parseErr.Offset, parseErr.Tag, tt.offset, tt.tag)
+			}
+		})
+	}
+
+	// The limits are not exceeded by the same data
+	if _, err := ParseBERWithLimits([]byte{0x71, 0x04, 0x72, 0x02, 0x81, 0x00}, Limits{MaxDepth: 3, MaxNodes: 3, MaxLength: 6}); err != nil {
+		t.Errorf("ParseBERWithLimits() unexpected error: %v", err)
+	}
+}
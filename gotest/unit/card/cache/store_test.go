@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ubavic/bas-celik/v2/card"
+)
+
+func testKey() []byte {
+	return bytes.Repeat([]byte{0x42}, KeySize)
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := Open(dir, testKey(), time.Hour)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+
+	id := []byte("card")
+	responses := map[string][]byte{"key": {0x01, 0x02, 0x90, 0x00}}
+
+	if _, err := store.Load(id); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	if err := store.Save(id, responses); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	loaded, err := store.Load(id)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(loaded["key"], responses["key"]) {
+		t.Errorf("unexpected responses %v", loaded)
+	}
+
+	// The data and the identifier are not stored in plain text
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("expected one entry, got %d", len(files))
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	if bytes.Contains(data, []byte{0x01, 0x02, 0x90, 0x00}) || bytes.Contains([]byte(files[0].Name()), id) {
+		t.Error("expected encrypted entry")
+	}
+
+	if err := store.Remove(id); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+
+	if _, err := store.Load(id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found error after removing, got %v", err)
+	}
+}
+
+func TestStore_Expired(t *testing.T) {
+	store, err := Open(t.TempDir(), testKey(), time.Hour)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	id := []byte("card")
+	if err := store.Save(id, map[string][]byte{}); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := store.Load(id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found error for expired entry, got %v", err)
+	}
+}
+
+func TestStore_WrongKey(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := Open(dir, testKey(), time.Hour)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+
+	id := []byte("card")
+	if err := store.Save(id, map[string][]byte{}); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	// Entries are named with the keyed hash, so the other key doesn't find the entry
+	other, err := Open(dir, bytes.Repeat([]byte{0x43}, KeySize), time.Hour)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+
+	if _, err := other.Load(id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found error with the other key, got %v", err)
+	}
+
+	// A swapped entry is not decrypted
+	path, _ := store.path(id)
+	otherPath, _ := store.path([]byte("other card"))
+	if err := os.Rename(path, otherPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Load([]byte("other card")); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected decryption error, got %v", err)
+	}
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	if _, err := Open(t.TempDir(), []byte{0x01}, time.Hour); err == nil {
+		t.Error("expected error for short key")
+	}
+
+	if _, err := Open(t.TempDir(), testKey(), 0); err == nil {
+		t.Error("expected error for zero time to live")
+	}
+}
+
+func TestSession(t *testing.T) {
+	store, err := Open(t.TempDir(), testKey(), time.Hour)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+
+	file := []byte{0x00, 0x00, 0x03, 0x00, 0x01, 0x02, 0x03}
+	virtualCard := card.MakeVirtualCard(card.GEMALTO_ATR_3, map[uint32][]byte{
+		0x0F02: file,
+		0x0F03: file,
+		0x0F04: file,
+		0x0F06: file,
+	})
+	virtualCard.AddApplication([]byte{0xF3, 0x81, 0x00, 0x00, 0x02, 0x53, 0x45, 0x52, 0x49, 0x44, 0x01})
+
+	readCard := func() bool {
+		session := NewSession(store, virtualCard)
+
+		cardDoc, err := card.DetectCardDocument(session.Card())
+		if err != nil {
+			t.Fatalf("DetectCardDocument() unexpected error: %v", err)
+		}
+
+		found, err := session.Load([]card.CardDocument{cardDoc})
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+
+		if err := cardDoc.ReadCard(); err != nil {
+			t.Fatalf("ReadCard() unexpected error: %v", err)
+		}
+
+		if err := session.Save(); err != nil {
+			t.Fatalf("Save() unexpected error: %v", err)
+		}
+
+		return found
+	}
+
+	if readCard() {
+		t.Error("expected card not to be cached on first reading")
+	}
+
+	if !readCard() {
+		t.Error("expected card to be cached on second reading")
+	}
+
+	disabled := NewSession(nil, virtualCard)
+	if disabled.Card() != card.Card(virtualCard) {
+		t.Error("expected the card to be used directly without the store")
+	}
+}
@@ -0,0 +1,145 @@
+package card
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Counts READ BINARY commands sent to the card
+type readCountingCard struct {
+	Card
+	reads int
+}
+
+func (card *readCountingCard) Transmit(apdu []byte) ([]byte, error) {
+	if len(apdu) >= 2 && apdu[1] == 0xB0 {
+		card.reads++
+	}
+
+	return card.Card.Transmit(apdu)
+}
+
+func cachedGemaltoCard(residence byte) *VirtualCard {
+	file := []byte{0x00, 0x00, 0x03, 0x00, 0x01, 0x02, 0x03}
+
+	photoLength := 600
+	photo := append([]byte{0x00, 0x00, byte(photoLength & 0xFF), byte(photoLength >> 8)}, bytes.Repeat([]byte{0xAB}, photoLength)...)
+
+	virtualCard := MakeVirtualCard(GEMALTO_ATR_3, map[uint32][]byte{
+		0x0F02: file,
+		0x0F03: file,
+		0x0F04: {0x00, 0x00, 0x01, 0x00, residence},
+		0x0F06: photo,
+	})
+	virtualCard.AddApplication(serIDAid)
+
+	return virtualCard
+}
+
+func TestCachingCard(t *testing.T) {
+	first := &readCountingCard{Card: cachedGemaltoCard(0x01)}
+	cachingCard := MakeCachingCard(first, nil)
+
+	gemalto := Gemalto{atr: GEMALTO_ATR_3, smartCard: cachingCard}
+	if err := gemalto.InitCard(); err != nil {
+		t.Fatalf("InitCard() unexpected error: %v", err)
+	}
+
+	if err := gemalto.ReadCard(); err != nil {
+		t.Fatalf("ReadCard() unexpected error: %v", err)
+	}
+
+	expected := gemalto.rawPhotoFile
+	responses := cachingCard.Responses()
+	if len(responses) == 0 {
+		t.Fatal("expected kept responses")
+	}
+
+	// The returning card is identified by reading the small files,
+	// and the remaining files are taken from the kept responses
+	second := &readCountingCard{Card: cachedGemaltoCard(0x01)}
+	cachingCard = MakeCachingCard(second, nil)
+	returning := Gemalto{atr: GEMALTO_ATR_3, smartCard: cachingCard}
+
+	if _, err := returning.ReadCardID(); err != nil {
+		t.Fatalf("ReadCardID() unexpected error: %v", err)
+	}
+	identified := second.reads
+
+	cachingCard.AddResponses(responses)
+	if err := returning.InitCard(); err != nil {
+		t.Fatalf("InitCard() unexpected error: %v", err)
+	}
+
+	if err := returning.ReadCard(); err != nil {
+		t.Fatalf("ReadCard() unexpected error: %v", err)
+	}
+
+	if second.reads != identified {
+		t.Errorf("expected no reads after identification, got %d", second.reads-identified)
+	}
+
+	if !bytes.Equal(returning.rawPhotoFile, expected) {
+		t.Error("unexpected photo from the kept responses")
+	}
+}
+
+func TestReadCardID(t *testing.T) {
+	read := func(residence byte) []byte {
+		gemalto := Gemalto{atr: GEMALTO_ATR_3, smartCard: cachedGemaltoCard(residence)}
+
+		id, err := gemalto.ReadCardID()
+		if err != nil {
+			t.Fatalf("ReadCardID() unexpected error: %v", err)
+		}
+
+		return id
+	}
+
+	if !bytes.Equal(read(0x01), read(0x01)) {
+		t.Error("expected the same identifier")
+	}
+
+	if bytes.Equal(read(0x01), read(0x02)) {
+		t.Error("expected different identifier after the residence file changed")
+	}
+
+	apollo := Apollo{atr: APOLLO_ATR, smartCard: MakeVirtualCard(APOLLO_ATR, nil)}
+	if _, err := apollo.ReadCardID(); err == nil {
+		t.Error("expected error for missing files")
+	}
+}
+
+func TestCachingCard_SelectContext(t *testing.T) {
+	virtualCard := MakeVirtualCard(APOLLO_ATR, map[uint32][]byte{
+		0x0101: {0x01},
+		0x0202: {0x02},
+	})
+	cachingCard := MakeCachingCard(virtualCard, nil)
+
+	readFile := func(name []byte) []byte {
+		if _, err := sendAPDU(cachingCard, 0x00, 0xA4, 0x00, 0x00, name, 0); err != nil {
+			t.Fatalf("selecting file: %v", err)
+		}
+
+		data, err := read(cachingCard, 0, 1)
+		if err != nil {
+			t.Fatalf("reading file: %v", err)
+		}
+
+		return data
+	}
+
+	// The same READ BINARY command of different files must not share the response
+	if data := readFile([]byte{0x01, 0x01}); !bytes.Equal(data, []byte{0x01}) {
+		t.Errorf("unexpected data %X", data)
+	}
+
+	if data := readFile([]byte{0x02, 0x02}); !bytes.Equal(data, []byte{0x02}) {
+		t.Errorf("unexpected data %X", data)
+	}
+
+	if len(cachingCard.Responses()) != 2 {
+		t.Errorf("expected 2 kept responses, got %d", len(cachingCard.Responses()))
+	}
+}
@@ -28,7 +28,8 @@ func Test_responseOK(t *testing.T) {
 		t.Run(
 			fmt.Sprintf("Case %d", i),
 			func(t *testing.T) {
-				res := responseOK(testCase.value)
+				rsp, err := ParseResponseAPDU(testCase.value)
+				res := err == nil && rsp.OK()
 
 				if res != testCase.result {
 					t.Errorf("Expected %t, but got %t", testCase.result, res)
@@ -81,6 +82,24 @@ func Test_read(t *testing.T) {
 			wantErr: true,
 			errSub:  "bad status",
 		},
+		{
+			name:     "status word not checked",
+			offset:   0x10,
+			length:   2,
+			resp:     []byte{0xAA, 0x6A, 0x82},
+			want:     []byte{0xAA},
+			wantP1P2: [2]byte{0x00, 0x10},
+			wantLe:   0x02,
+		},
+		{
+			name:     "error status without data",
+			offset:   0x10,
+			length:   2,
+			resp:     []byte{0x6B, 0x00},
+			want:     []byte{},
+			wantP1P2: [2]byte{0x00, 0x10},
+			wantLe:   0x02,
+		},
 		{
 			name:     "length capped to 0xFF",
 			offset:   0,
@@ -141,8 +160,8 @@ func Test_DetectCardDocument(t *testing.T) {
 		atr        []byte
 		expectType CardDocumentType
 	}{
-		{"apollo", APOLLO_ATR, ApolloIdDocumentCardType},
-		{"gemalto", GEMALTO_ATR_1, GemaltoIdDocumentCardType},
+		{"apollo", APOLLO_ATR, ApolloIDDocumentCardType},
+		{"gemalto", GEMALTO_ATR_1, GemaltoIDDocumentCardType},
 		{"vehicle", VEHICLE_ATR_2, VehicleDocumentCardType},
 	}
 
@@ -172,11 +191,11 @@ func Test_DetectCardDocument(t *testing.T) {
 			}
 
 			switch tt.expectType {
-			case ApolloIdDocumentCardType:
+			case ApolloIDDocumentCardType:
 				if _, ok := doc.(*Apollo); !ok {
 					t.Fatalf("expected Apollo card, got %T", doc)
 				}
-			case GemaltoIdDocumentCardType:
+			case GemaltoIDDocumentCardType:
 				if _, ok := doc.(*Gemalto); !ok {
 					t.Fatalf("expected Gemalto card, got %T", doc)
 				}
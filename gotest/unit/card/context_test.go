@@ -0,0 +1,134 @@
+package card
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ebfe/scard"
+	"github.com/stretchr/testify/mock"
+	"github.com/ubavic/bas-celik/v2/card/carderrors"
+	testhelpers "github.com/ubavic/bas-celik/v2/test_helpers"
+)
+
+// Calls the function after the given number of transmitted commands
+type countingCard struct {
+	Card
+	count   int
+	after   int
+	onCount func()
+}
+
+func (card *countingCard) Transmit(apdu []byte) ([]byte, error) {
+	card.count++
+	if card.count == card.after {
+		card.onCount()
+	}
+
+	return card.Card.Transmit(apdu)
+}
+
+func gemaltoVirtualCard() *VirtualCard {
+	file := []byte{0x00, 0x00, 0x03, 0x00, 0x01, 0x02, 0x03}
+
+	return MakeVirtualCard(GEMALTO_ATR_3, map[uint32][]byte{
+		0x0F02: file,
+		0x0F03: file,
+		0x0F04: file,
+		0x0F06: file,
+	})
+}
+
+func TestGemalto_ReadCardContext(t *testing.T) {
+	gemalto := Gemalto{atr: GEMALTO_ATR_3, smartCard: gemaltoVirtualCard()}
+
+	if err := gemalto.ReadCardContext(context.Background()); err != nil {
+		t.Fatalf("ReadCardContext() unexpected error: %v", err)
+	}
+
+	if _, ok := gemalto.smartCard.(*VirtualCard); !ok {
+		t.Errorf("expected original card to be restored, got %T", gemalto.smartCard)
+	}
+}
+
+func TestGemalto_ReadCardContext_Cancelled(t *testing.T) {
+	cm := &testhelpers.CardMock{}
+	gemalto := Gemalto{atr: GEMALTO_ATR_3, smartCard: cm}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := gemalto.ReadCardContext(ctx)
+	if !errors.Is(err, carderrors.ErrCancelled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation error, got %v", err)
+	}
+
+	cm.AssertNotCalled(t, "Transmit", mock.Anything)
+}
+
+func TestGemalto_ReadCardContext_CancelledWhileReading(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel after selecting and reading the header of the first file
+	counter := &countingCard{Card: gemaltoVirtualCard(), after: 2, onCount: cancel}
+	gemalto := Gemalto{atr: GEMALTO_ATR_3, smartCard: counter}
+
+	err := gemalto.ReadCardContext(ctx)
+	if !errors.Is(err, carderrors.ErrCancelled) {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+
+	if counter.count != 2 {
+		t.Errorf("expected no commands after cancellation, got %d commands", counter.count)
+	}
+
+	if gemalto.smartCard != Card(counter) {
+		t.Errorf("expected original card to be restored, got %T", gemalto.smartCard)
+	}
+}
+
+func TestMedicalCard_ReadFileContext_DeadlineExceeded(t *testing.T) {
+	medical := MedicalCard{atr: MEDICAL_ATR_2, smartCard: &testhelpers.CardMock{}}
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	_, err := medical.ReadFileContext(ctx, MED_DOCUMENT_FILE_LOC)
+	if !errors.Is(err, carderrors.ErrCancelled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+}
+
+func TestVehicleCard_InitCardContext_CardRemoved(t *testing.T) {
+	cm := &testhelpers.CardMock{}
+	cm.On("Transmit", mock.Anything).Return(nil, scard.ErrRemovedCard)
+
+	vehicle := VehicleCard{atr: VEHICLE_ATR_0, smartCard: cm}
+
+	err := vehicle.InitCardContext(context.Background())
+	if !errors.Is(err, carderrors.ErrCardRemoved) || !errors.Is(err, scard.ErrRemovedCard) {
+		t.Errorf("expected card removed error, got %v", err)
+	}
+
+	if errors.Is(err, carderrors.ErrCancelled) {
+		t.Errorf("unexpected cancellation error %v", err)
+	}
+}
+
+func TestApollo_ReadFileContext(t *testing.T) {
+	virtualCard := MakeVirtualCard(APOLLO_ATR, map[uint32][]byte{
+		0x0F02: {0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0xAB, 0xCD},
+	})
+	apollo := Apollo{atr: APOLLO_ATR, smartCard: virtualCard}
+
+	data, err := apollo.ReadFileContext(context.Background(), ID_DOCUMENT_FILE_LOC)
+	if err != nil {
+		t.Fatalf("ReadFileContext() unexpected error: %v", err)
+	}
+
+	if len(data) != 2 || data[0] != 0xAB || data[1] != 0xCD {
+		t.Errorf("unexpected data %X", data)
+	}
+}
@@ -0,0 +1,203 @@
+package card
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"maps"
+	"testing"
+
+	"github.com/ebfe/scard"
+	testhelpers "github.com/ubavic/bas-celik/v2/test_helpers"
+)
+
+func derObject(t *testing.T, class, tag int, compound bool, content ...[]byte) []byte {
+	t.Helper()
+	return mustMarshal(t, asn1.RawValue{Class: class, Tag: tag, IsCompound: compound, Bytes: bytes.Join(content, nil)}, "")
+}
+
+func pkcs15PathObject(t *testing.T, path []byte) []byte {
+	t.Helper()
+	return derObject(t, asn1.ClassUniversal, asn1.TagSequence, true, derObject(t, asn1.ClassUniversal, asn1.TagOctetString, false, path))
+}
+
+// Certificate object of the CDF with the given certificate value
+func pkcs15CertificateObject(t *testing.T, label string, value []byte) []byte {
+	t.Helper()
+	return pkcs15CertificateObjectWithID(t, label, []byte{0x01}, value)
+}
+
+func pkcs15CertificateObjectWithID(t *testing.T, label string, id, value []byte) []byte {
+	t.Helper()
+	return derObject(t, asn1.ClassUniversal, asn1.TagSequence, true,
+		derObject(t, asn1.ClassUniversal, asn1.TagSequence, true, derObject(t, asn1.ClassUniversal, asn1.TagUTF8String, false, []byte(label))),
+		derObject(t, asn1.ClassUniversal, asn1.TagSequence, true, derObject(t, asn1.ClassUniversal, asn1.TagOctetString, false, id)),
+		derObject(t, asn1.ClassContextSpecific, 1, true,
+			derObject(t, asn1.ClassUniversal, asn1.TagSequence, true, value)),
+	)
+}
+
+// RSA private key object of the PrKDF with the given identifier and key reference
+func pkcs15PrivateKeyObject(t *testing.T, label string, id []byte, reference int) []byte {
+	t.Helper()
+	return derObject(t, asn1.ClassUniversal, asn1.TagSequence, true,
+		derObject(t, asn1.ClassUniversal, asn1.TagSequence, true, derObject(t, asn1.ClassUniversal, asn1.TagUTF8String, false, []byte(label))),
+		derObject(t, asn1.ClassUniversal, asn1.TagSequence, true,
+			derObject(t, asn1.ClassUniversal, asn1.TagOctetString, false, id),
+			mustMarshal(t, asn1.BitString{Bytes: []byte{0x20}, BitLength: 3}, ""),
+			mustMarshal(t, reference, ""),
+		),
+		derObject(t, asn1.ClassContextSpecific, 1, true,
+			derObject(t, asn1.ClassUniversal, asn1.TagSequence, true, pkcs15PathObject(t, []byte{0x3F, 0x00, 0x00, 0x01}))),
+	)
+}
+
+func TestApollo_LoadCertificates(t *testing.T) {
+	indirect := newTestSigner(t).certificate
+	direct := newTestSigner(t).certificate
+
+	padding := bytes.Repeat([]byte{0xFF}, 16)
+
+	odf := append(derObject(t, asn1.ClassContextSpecific, 4, true, pkcs15PathObject(t, []byte{0x44, 0x01})), padding...)
+	cdf := bytes.Join([][]byte{
+		pkcs15CertificateObject(t, "Signing", pkcs15PathObject(t, []byte{0x3F, 0x00, 0x43, 0x01})),
+		pkcs15CertificateObject(t, "Authentication", derObject(t, asn1.ClassContextSpecific, 0, true, direct.Raw)),
+		padding,
+	}, nil)
+
+	virtualCard := MakeVirtualCard(APOLLO_ATR, map[uint32][]byte{
+		0x5031: odf,
+		0x4401: cdf,
+		0x4301: append(bytes.Clone(indirect.Raw), padding...),
+	})
+	virtualCard.AddApplication(pkcs15Aid)
+
+	apollo := Apollo{atr: APOLLO_ATR, smartCard: virtualCard}
+
+	if err := apollo.LoadCertificates(); err != nil {
+		t.Fatalf("LoadCertificates() unexpected error: %v", err)
+	}
+
+	certificates := apollo.GetCertificates()
+	if len(certificates) != 2 {
+		t.Fatalf("expected 2 certificates, got %d", len(certificates))
+	}
+
+	if !bytes.Equal(certificates[0].Raw, indirect.Raw) || !bytes.Equal(certificates[1].Raw, direct.Raw) {
+		t.Errorf("unexpected certificates")
+	}
+}
+
+func TestApollo_LoadCertificatesWithoutApplication(t *testing.T) {
+	apollo := Apollo{atr: APOLLO_ATR, smartCard: MakeVirtualCard(APOLLO_ATR, nil)}
+
+	if err := apollo.LoadCertificates(); err == nil {
+		t.Fatal("expected error without the PKCS-15 application")
+	}
+
+	if len(apollo.GetCertificates()) != 0 {
+		t.Error("expected no certificates")
+	}
+}
+
+func TestReadPkcs15KeyReferences(t *testing.T) {
+	padding := bytes.Repeat([]byte{0xFF}, 16)
+
+	odf := bytes.Join([][]byte{
+		derObject(t, asn1.ClassContextSpecific, 0, true, pkcs15PathObject(t, []byte{0x44, 0x02})),
+		derObject(t, asn1.ClassContextSpecific, 4, true, pkcs15PathObject(t, []byte{0x44, 0x01})),
+		padding,
+	}, nil)
+	prkdf := bytes.Join([][]byte{
+		pkcs15PrivateKeyObject(t, "Authentication", []byte{0x45, 0x01}, 0x81),
+		pkcs15PrivateKeyObject(t, "Signing", []byte{0x45, 0x02}, 0x82),
+		padding,
+	}, nil)
+	cdf := bytes.Join([][]byte{
+		pkcs15CertificateObjectWithID(t, "Signing", []byte{0x45, 0x02}, pkcs15PathObject(t, []byte{0x3F, 0x00, 0x71, 0x03})),
+		pkcs15CertificateObjectWithID(t, "Authentication", []byte{0x45, 0x01}, pkcs15PathObject(t, []byte{0x3F, 0x00, 0x71, 0x02})),
+		pkcs15CertificateObjectWithID(t, "CA", []byte{0x45, 0x03}, pkcs15PathObject(t, []byte{0x3F, 0x00, 0x71, 0x04})),
+		padding,
+	}, nil)
+
+	virtualCard := MakeVirtualCard(GEMALTO_ATR_1, map[uint32][]byte{
+		0x5031: odf,
+		0x4401: cdf,
+		0x4402: prkdf,
+	})
+
+	references, err := readPkcs15KeyReferences(virtualCard)
+	if err != nil {
+		t.Fatalf("readPkcs15KeyReferences() unexpected error: %v", err)
+	}
+
+	expected := map[string]byte{"7102": 0x81, "7103": 0x82}
+	if !maps.Equal(references, expected) {
+		t.Errorf("expected %v, got %v", expected, references)
+	}
+
+	if _, err := readPkcs15KeyReferences(MakeVirtualCard(GEMALTO_ATR_1, nil)); err == nil {
+		t.Error("expected error for missing object directory")
+	}
+}
+
+func TestReadTransparentFile(t *testing.T) {
+	// Size divisible by the read size, so the end is found by reading past it
+	file := bytes.Repeat([]byte{0xAB}, 2*maxShortCommandData)
+	virtualCard := MakeVirtualCard(APOLLO_ATR, map[uint32][]byte{0x4301: file})
+
+	data, err := readTransparentFile(virtualCard, []byte{0x43, 0x01})
+	if err != nil {
+		t.Fatalf("readTransparentFile() unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(data, file) {
+		t.Errorf("expected %d bytes, got %d", len(file), len(data))
+	}
+
+	if _, err := readTransparentFile(virtualCard, []byte{0x43, 0x02}); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestApollo_VerifyPin(t *testing.T) {
+	cm := &testhelpers.CardMock{}
+	cm.On("BeginTransaction").Return(nil).Once()
+	cm.On("Transmit", append([]byte{0x00, 0xA4, 0x04, 0x00, byte(len(pkcs15Aid))}, pkcs15Aid...)).Return([]byte{0x90, 0x00}, nil).Once()
+	cm.On("Transmit", []byte{0x00, 0x20, 0x00, 0x80}).Return([]byte{0x63, 0xC2}, nil).Once()
+	cm.On("Transmit", []byte{0x00, 0x20, 0x00, 0x80, 0x08, '1', '2', '3', '4', 0, 0, 0, 0}).Return([]byte{0x63, 0xC1}, nil).Once()
+	cm.On("EndTransaction", scard.LeaveCard).Return(nil).Once()
+
+	apollo := Apollo{atr: APOLLO_ATR, smartCard: cm}
+
+	triesLeft, err := apollo.VerifyPin("1234")
+	if err == nil {
+		t.Fatal("expected error for wrong pin")
+	}
+
+	if triesLeft != 1 {
+		t.Errorf("VerifyPin() tries = %d, want 1", triesLeft)
+	}
+
+	cm.AssertExpectations(t)
+}
+
+func TestCryptoCard(t *testing.T) {
+	documents := map[string]CardDocument{
+		"apollo":  &Apollo{},
+		"gemalto": &Gemalto{},
+	}
+
+	for name, document := range documents {
+		if _, ok := document.(CryptoCard); !ok {
+			t.Errorf("expected %s to be a crypto card", name)
+		}
+	}
+
+	if _, ok := CardDocument(&MedicalCard{}).(CryptoCard); ok {
+		t.Error("medical card is not expected to be a crypto card")
+	}
+
+	if _, ok := CardDocument(&Apollo{}).(VerifiableCard); ok {
+		t.Error("apollo card is not expected to be verifiable")
+	}
+}
@@ -0,0 +1,80 @@
+package card
+
+import (
+	"errors"
+	"testing"
+)
+
+var (
+	serIDAid   = []byte{0xF3, 0x81, 0x00, 0x00, 0x02, 0x53, 0x45, 0x52, 0x49, 0x44, 0x01}
+	vehicleAid = []byte{0xA0, 0x00, 0x00, 0x01, 0x51, 0x00, 0x00}
+)
+
+// Card with the ID and the vehicle applications, and without the medical application
+func multiApplicationCard() *VirtualCard {
+	virtualCard := MakeVirtualCard(GEMALTO_ATR_3, map[uint32][]byte{
+		0x0F02: {0x00, 0x00, 0x03, 0x00, 0x01, 0x02, 0x03},
+	})
+	virtualCard.AddApplication(serIDAid)
+	virtualCard.AddApplication(vehicleAid)
+
+	return virtualCard
+}
+
+func TestDetectCardDocuments(t *testing.T) {
+	cards, err := DetectCardDocuments(multiApplicationCard())
+	if err != nil {
+		t.Fatalf("DetectCardDocuments() unexpected error: %v", err)
+	}
+
+	if len(cards) != 2 {
+		t.Fatalf("expected 2 documents, got %d", len(cards))
+	}
+
+	if _, ok := cards[0].(*Gemalto); !ok {
+		t.Errorf("expected Gemalto card first, got %T", cards[0])
+	}
+
+	if _, ok := cards[1].(*VehicleCard); !ok {
+		t.Errorf("expected Vehicle card second, got %T", cards[1])
+	}
+}
+
+func TestDetectCardDocument_FirstDocument(t *testing.T) {
+	cardDoc, err := DetectCardDocument(multiApplicationCard())
+	if err != nil {
+		t.Fatalf("DetectCardDocument() unexpected error: %v", err)
+	}
+
+	if _, ok := cardDoc.(*Gemalto); !ok {
+		t.Errorf("expected Gemalto card, got %T", cardDoc)
+	}
+}
+
+func TestDetectCardDocuments_NoApplication(t *testing.T) {
+	cards, err := DetectCardDocuments(MakeVirtualCard(GEMALTO_ATR_3, nil))
+	if err == nil || errors.Is(err, ErrUnknownCard) {
+		t.Fatalf("expected unexpected card type error, got %v", err)
+	}
+
+	if len(cards) != 0 {
+		t.Errorf("expected no documents, got %d", len(cards))
+	}
+}
+
+func TestDetectCardDocuments_UnknownCard(t *testing.T) {
+	atr := Atr{0x3B, 0x00}
+
+	cards, err := DetectCardDocuments(MakeVirtualCard(atr, nil))
+	if !errors.Is(err, ErrUnknownCard) {
+		t.Fatalf("expected unknown card error, got %v", err)
+	}
+
+	if len(cards) != 1 {
+		t.Fatalf("expected unknown card document, got %d documents", len(cards))
+	}
+
+	if _, ok := cards[0].(*UnknownDocumentCard); !ok {
+		t.Errorf("expected unknown card document, got %T", cards[0])
+	}
+}
@@ -0,0 +1,188 @@
+package card
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ebfe/scard"
+	"github.com/stretchr/testify/mock"
+	testhelpers "github.com/ubavic/bas-celik/v2/test_helpers"
+)
+
+func apduWithHeader(header ...byte) any {
+	return mock.MatchedBy(func(apdu []byte) bool {
+		return bytes.HasPrefix(apdu, header)
+	})
+}
+
+func TestGemaltoVerifyPin(t *testing.T) {
+	tests := []struct {
+		name      string
+		counter   []byte
+		response  []byte
+		triesLeft int
+		errSub    string
+	}{
+		{"success", []byte{0x63, 0xC3}, []byte{0x90, 0x00}, -1, ""},
+		{"wrong pin", []byte{0x63, 0xC3}, []byte{0x63, 0xC2}, 2, "verifying pin"},
+		{"blocked", []byte{0x63, 0xC1}, []byte{0x69, 0x83}, 0, "verifying pin"},
+		{"blocked before verification", []byte{0x69, 0x83}, nil, 0, "authentication method blocked"},
+		{"counter not reported", []byte{0x90, 0x00}, []byte{0x90, 0x00}, -1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &testhelpers.CardMock{}
+			cm.On("BeginTransaction").Return(nil).Once()
+			cm.On("Transmit", apduWithHeader(0x00, 0xA4, 0x04, 0x00)).Return([]byte{0x90, 0x00}, nil).Once()
+			cm.On("Transmit", []byte{0x00, 0x20, 0x00, 0x80}).Return(tt.counter, nil).Once()
+			if tt.response != nil {
+				cm.On("Transmit", []byte{0x00, 0x20, 0x00, 0x80, 0x08, '1', '2', '3', '4', 0, 0, 0, 0}).Return(tt.response, nil).Once()
+			}
+			cm.On("EndTransaction", scard.LeaveCard).Return(nil).Once()
+
+			g := Gemalto{smartCard: cm}
+
+			triesLeft, err := g.VerifyPin("1234")
+			if tt.errSub == "" && err != nil {
+				t.Fatalf("VerifyPin() unexpected error: %v", err)
+			}
+
+			if tt.errSub != "" && (err == nil || !strings.Contains(err.Error(), tt.errSub)) {
+				t.Fatalf("VerifyPin() expected error containing %q, got %v", tt.errSub, err)
+			}
+
+			if triesLeft != tt.triesLeft {
+				t.Errorf("VerifyPin() tries = %d, want %d", triesLeft, tt.triesLeft)
+			}
+
+			cm.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGemaltoVerifyPinInvalid(t *testing.T) {
+	cm := &testhelpers.CardMock{}
+	cm.On("BeginTransaction").Return(nil).Once()
+	cm.On("Transmit", apduWithHeader(0x00, 0xA4, 0x04, 0x00)).Return([]byte{0x90, 0x00}, nil).Once()
+	cm.On("EndTransaction", scard.LeaveCard).Return(nil).Once()
+
+	g := Gemalto{smartCard: cm}
+
+	if _, err := g.VerifyPin("12a4"); err == nil || !strings.Contains(err.Error(), "pin not valid") {
+		t.Fatalf("VerifyPin() expected validation error, got %v", err)
+	}
+
+	cm.AssertExpectations(t)
+}
+
+func TestGemaltoReadPinTriesLeft(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  []byte
+		triesLeft int
+	}{
+		{"three tries", []byte{0x63, 0xC3}, 3},
+		{"one try", []byte{0x63, 0xC1}, 1},
+		{"blocked", []byte{0x69, 0x83}, 0},
+		{"already verified", []byte{0x90, 0x00}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &testhelpers.CardMock{}
+			cm.On("BeginTransaction").Return(nil).Once()
+			cm.On("Transmit", apduWithHeader(0x00, 0xA4, 0x04, 0x00)).Return([]byte{0x90, 0x00}, nil).Once()
+			cm.On("Transmit", []byte{0x00, 0x20, 0x00, 0x80}).Return(tt.response, nil).Once()
+			cm.On("EndTransaction", scard.LeaveCard).Return(nil).Once()
+
+			g := Gemalto{smartCard: cm}
+
+			triesLeft, err := g.ReadPinTriesLeft()
+			if err != nil {
+				t.Fatalf("ReadPinTriesLeft() unexpected error: %v", err)
+			}
+
+			if triesLeft != tt.triesLeft {
+				t.Errorf("ReadPinTriesLeft() tries = %d, want %d", triesLeft, tt.triesLeft)
+			}
+
+			cm.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGemaltoResetRetryCounter(t *testing.T) {
+	expected := []byte{
+		0x00, 0x2C, 0x00, 0x80, 0x10,
+		'8', '7', '6', '5', '4', '3', '2', '1',
+		'5', '6', '7', '8', 0, 0, 0, 0,
+	}
+
+	tests := []struct {
+		name      string
+		response  []byte
+		triesLeft int
+		errSub    string
+	}{
+		{"success", []byte{0x90, 0x00}, -1, ""},
+		{"wrong puk", []byte{0x63, 0xC1}, 1, "resetting retry counter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &testhelpers.CardMock{}
+			cm.On("BeginTransaction").Return(nil).Once()
+			cm.On("Transmit", apduWithHeader(0x00, 0xA4, 0x04, 0x00)).Return([]byte{0x90, 0x00}, nil).Once()
+			cm.On("Transmit", expected).Return(tt.response, nil).Once()
+			cm.On("EndTransaction", scard.LeaveCard).Return(nil).Once()
+
+			g := Gemalto{smartCard: cm}
+
+			triesLeft, err := g.ResetRetryCounter("87654321", "5678")
+			if tt.errSub == "" && err != nil {
+				t.Fatalf("ResetRetryCounter() unexpected error: %v", err)
+			}
+
+			if tt.errSub != "" && (err == nil || !strings.Contains(err.Error(), tt.errSub)) {
+				t.Fatalf("ResetRetryCounter() expected error containing %q, got %v", tt.errSub, err)
+			}
+
+			if triesLeft != tt.triesLeft {
+				t.Errorf("ResetRetryCounter() tries = %d, want %d", triesLeft, tt.triesLeft)
+			}
+
+			cm.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGemaltoResetRetryCounterInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		puk    string
+		newPin string
+		errSub string
+	}{
+		{"invalid puk", "12", "5678", "puk not valid"},
+		{"invalid new pin", "87654321", "123456789", "new pin not valid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &testhelpers.CardMock{}
+			cm.On("BeginTransaction").Return(nil).Once()
+			cm.On("Transmit", apduWithHeader(0x00, 0xA4, 0x04, 0x00)).Return([]byte{0x90, 0x00}, nil).Once()
+			cm.On("EndTransaction", scard.LeaveCard).Return(nil).Once()
+
+			g := Gemalto{smartCard: cm}
+
+			if _, err := g.ResetRetryCounter(tt.puk, tt.newPin); err == nil || !strings.Contains(err.Error(), tt.errSub) {
+				t.Fatalf("ResetRetryCounter() expected error containing %q, got %v", tt.errSub, err)
+			}
+
+			cm.AssertExpectations(t)
+		})
+	}
+}
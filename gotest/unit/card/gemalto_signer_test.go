@@ -0,0 +1,213 @@
+package card
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ebfe/scard"
+	testhelpers "github.com/ubavic/bas-celik/v2/test_helpers"
+)
+
+// DigestInfo prefix for SHA-256 from RFC 8017
+const sha256DigestInfoPrefix = "3031300d060960864801650304020105000420"
+
+// Expects the commands sent by Signer to verify the PIN 1234
+func expectSignerPin(cm *testhelpers.CardMock, response []byte) {
+	cm.On("BeginTransaction").Return(nil).Once()
+	cm.On("Transmit", apduWithHeader(0x00, 0xA4, 0x04, 0x00)).Return([]byte{0x90, 0x00}, nil).Once()
+	cm.On("Transmit", []byte{0x00, 0x20, 0x00, 0x80}).Return([]byte{0x63, 0xC3}, nil).Once()
+	cm.On("Transmit", []byte{0x00, 0x20, 0x00, 0x80, 0x08, '1', '2', '3', '4', 0, 0, 0, 0}).Return(response, nil).Once()
+	cm.On("EndTransaction", scard.LeaveCard).Return(nil).Once()
+}
+
+func TestGemaltoSigner_Sign(t *testing.T) {
+	signer := newTestSigner(t)
+	other := newTestSigner(t)
+
+	digest := sha256.Sum256([]byte("document"))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, signer.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("signing: %v", err)
+	}
+
+	digestInfo, _ := hex.DecodeString(sha256DigestInfoPrefix)
+	digestInfo = append(digestInfo, digest[:]...)
+
+	pso := append([]byte{0x00, 0x2A, 0x9E, 0x9A, byte(len(digestInfo))}, digestInfo...)
+	pso = append(pso, 0x00)
+
+	cm := &testhelpers.CardMock{}
+	expectSignerPin(cm, []byte{0x90, 0x00})
+	cm.On("BeginTransaction").Return(nil).Once()
+	cm.On("Transmit", apduWithHeader(0x00, 0xA4, 0x04, 0x00)).Return([]byte{0x90, 0x00}, nil).Once()
+	cm.On("Transmit", []byte{0x00, 0x22, 0x41, 0xB6, 0x06, 0x80, 0x01, 0x02, 0x84, 0x01, 0x82}).Return([]byte{0x90, 0x00}, nil).Once()
+	cm.On("Transmit", pso).Return(append(signature, 0x90, 0x00), nil).Once()
+	cm.On("EndTransaction", scard.LeaveCard).Return(nil).Once()
+
+	gemalto := Gemalto{
+		smartCard:     cm,
+		certificates:  []*x509.Certificate{other.certificate, signer.certificate},
+		keyReferences: map[*x509.Certificate]byte{other.certificate: 0x81, signer.certificate: 0x82},
+	}
+
+	cardSigner, err := gemalto.Signer(signer.certificate, "1234")
+	if err != nil {
+		t.Fatalf("Signer() unexpected error: %v", err)
+	}
+
+	publicKey, ok := cardSigner.Public().(*rsa.PublicKey)
+	if !ok || !publicKey.Equal(&signer.key.PublicKey) {
+		t.Fatalf("Public() returned unexpected key %v", cardSigner.Public())
+	}
+
+	result, err := cardSigner.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Sign() unexpected error: %v", err)
+	}
+
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], result); err != nil {
+		t.Errorf("signature not valid: %v", err)
+	}
+
+	cm.AssertExpectations(t)
+}
+
+func TestGemaltoSigner_WrongPin(t *testing.T) {
+	signer := newTestSigner(t)
+
+	cm := &testhelpers.CardMock{}
+	expectSignerPin(cm, []byte{0x63, 0xC2})
+
+	gemalto := Gemalto{
+		smartCard:     cm,
+		certificates:  []*x509.Certificate{signer.certificate},
+		keyReferences: map[*x509.Certificate]byte{signer.certificate: 0x81},
+	}
+
+	_, err := gemalto.Signer(signer.certificate, "1234")
+	if err == nil || !strings.Contains(err.Error(), "verifying pin") || !strings.Contains(err.Error(), "tries left: 2") {
+		t.Fatalf("Signer() expected PIN error, got %v", err)
+	}
+
+	cm.AssertExpectations(t)
+}
+
+func TestGemaltoSigner_Errors(t *testing.T) {
+	signer := newTestSigner(t)
+	other := newTestSigner(t)
+
+	withoutKey := newTestSigner(t)
+
+	cm := &testhelpers.CardMock{}
+	expectSignerPin(cm, []byte{0x90, 0x00})
+
+	gemalto := Gemalto{
+		smartCard:     cm,
+		certificates:  []*x509.Certificate{signer.certificate, withoutKey.certificate},
+		keyReferences: map[*x509.Certificate]byte{signer.certificate: 0x81},
+	}
+
+	// Errors are returned before the PIN is sent to the card
+	if _, err := gemalto.Signer(other.certificate, "1234"); err == nil {
+		t.Error("Signer() expected error for certificate not on the card")
+	}
+
+	if _, err := gemalto.Signer(withoutKey.certificate, "1234"); err == nil {
+		t.Error("Signer() expected error for certificate without a private key")
+	}
+
+	if _, err := gemalto.Signer(signer.certificate, "12"); err == nil {
+		t.Error("Signer() expected error for invalid PIN")
+	}
+
+	cardSigner, err := gemalto.Signer(signer.certificate, "1234")
+	if err != nil {
+		t.Fatalf("Signer() unexpected error: %v", err)
+	}
+
+	// Errors are returned before any command is sent to the card
+	digest := sha256.Sum256([]byte("document"))
+
+	if _, err := cardSigner.Sign(nil, digest[:], &rsa.PSSOptions{Hash: crypto.SHA256}); err == nil {
+		t.Error("Sign() expected error for PSS")
+	}
+
+	if _, err := cardSigner.Sign(nil, digest[:20], crypto.SHA256); err == nil {
+		t.Error("Sign() expected error for wrong digest length")
+	}
+
+	if _, err := cardSigner.Sign(nil, digest[:], crypto.MD5); err == nil {
+		t.Error("Sign() expected error for unsupported hash")
+	}
+
+	cm.AssertExpectations(t)
+}
+
+func TestGemaltoSigner_SignChained(t *testing.T) {
+	issuer := newTestSigner(t)
+
+	// Only the size of the key matters, so the modulus isn't a real RSA modulus
+	modulus := new(big.Int).Lsh(big.NewInt(1), 4095)
+	template := x509.Certificate{SerialNumber: big.NewInt(2)}
+	der, err := x509.CreateCertificate(rand.Reader, &template, issuer.certificate, &rsa.PublicKey{N: modulus, E: 65537}, issuer.key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	first := append([]byte{0x10, 0x2A, 0x9E, 0x9A, 0xFF}, data[:255]...)
+	last := append([]byte{0x00, 0x2A, 0x9E, 0x9A, 0x00, 0x00, 0x2D}, data[255:]...)
+	last = append(last, 0x02, 0x00)
+	signature := make([]byte, 512)
+
+	cm := &testhelpers.CardMock{}
+	expectSignerPin(cm, []byte{0x90, 0x00})
+	cm.On("BeginTransaction").Return(nil).Once()
+	cm.On("Transmit", apduWithHeader(0x00, 0xA4, 0x04, 0x00)).Return([]byte{0x90, 0x00}, nil).Once()
+	cm.On("Transmit", apduWithHeader(0x00, 0x22, 0x41, 0xB6)).Return([]byte{0x90, 0x00}, nil).Once()
+	cm.On("Transmit", first).Return([]byte{0x90, 0x00}, nil).Once()
+	cm.On("Transmit", last).Return(append(signature, 0x90, 0x00), nil).Once()
+	cm.On("EndTransaction", scard.LeaveCard).Return(nil).Once()
+
+	gemalto := Gemalto{
+		smartCard:     cm,
+		certificates:  []*x509.Certificate{certificate},
+		keyReferences: map[*x509.Certificate]byte{certificate: 0x81},
+	}
+
+	cardSigner, err := gemalto.Signer(certificate, "1234")
+	if err != nil {
+		t.Fatalf("Signer() unexpected error: %v", err)
+	}
+
+	result, err := cardSigner.Sign(nil, data, crypto.Hash(0))
+	if err != nil {
+		t.Fatalf("Sign() unexpected error: %v", err)
+	}
+
+	if len(result) != len(signature) {
+		t.Errorf("expected %d byte signature, got %d", len(signature), len(result))
+	}
+
+	cm.AssertExpectations(t)
+
+	if _, err := cardSigner.Sign(nil, make([]byte, 502), crypto.Hash(0)); err == nil {
+		t.Error("Sign() expected error for data longer than the key allows")
+	}
+}
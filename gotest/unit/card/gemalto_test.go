@@ -230,9 +230,9 @@ func TestGemaltoGetDocument(t *testing.T) {
 				if err != nil {
 					t.Fatalf("GetDocument() unexpected error: %v", err)
 				}
-					id, ok := d.(*doc.IdDocument)
+					id, ok := d.(*doc.IDDocument)
 					if !ok || id == nil {
-						t.Fatalf("GetDocument() returned %T, want *IdDocument", d)
+						t.Fatalf("GetDocument() returned %T, want *IDDocument", d)
 				}
 				if id.Portrait == nil {
 					t.Fatalf("GetDocument() missing portrait image")
@@ -81,7 +81,10 @@ func Test_descramble(t *testing.T) {
 
 func TestMedicalInitCard(t *testing.T) {
 	s1 := []byte{0xF3, 0x81, 0x00, 0x00, 0x02, 0x53, 0x45, 0x52, 0x56, 0x53, 0x5A, 0x4B, 0x01}
-	expectedAPDU := buildAPDU(0x00, 0xA4, 0x04, 0x00, s1, 0)
+	expectedAPDU, err := buildAPDU(0x00, 0xA4, 0x04, 0x00, s1, 0)
+	if err != nil {
+		t.Fatalf("buildAPDU() error = %v", err)
+	}
 
 	tests := []struct {
 		name      string
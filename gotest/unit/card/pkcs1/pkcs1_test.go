@@ -0,0 +1,61 @@
+package pkcs1
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"testing"
+)
+
+func TestEncodeDigestInfo(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	hashes := []crypto.Hash{crypto.SHA1, crypto.SHA224, crypto.SHA256, crypto.SHA384, crypto.SHA512}
+
+	for _, hash := range hashes {
+		t.Run(hash.String(), func(t *testing.T) {
+			h := hash.New()
+			h.Write([]byte("document"))
+			digest := h.Sum(nil)
+
+			data, err := EncodeDigestInfo(hash, digest)
+			if err != nil {
+				t.Fatalf("EncodeDigestInfo() unexpected error: %v", err)
+			}
+
+			// The raw signature of the DigestInfo has to be the PKCS #1 v1.5 signature of the digest
+			signature, err := rsa.SignPKCS1v15(nil, key, crypto.Hash(0), data)
+			if err != nil {
+				t.Fatalf("signing: %v", err)
+			}
+
+			if err := rsa.VerifyPKCS1v15(&key.PublicKey, hash, digest, signature); err != nil {
+				t.Errorf("signature not valid: %v", err)
+			}
+		})
+	}
+}
+
+func TestEncodeDigestInfo_Errors(t *testing.T) {
+	digest := bytes.Repeat([]byte{0x01}, 32)
+
+	data, err := EncodeDigestInfo(crypto.Hash(0), digest)
+	if err != nil || !bytes.Equal(data, digest) {
+		t.Errorf("expected the digest unchanged, got %X, %v", data, err)
+	}
+
+	if _, err := EncodeDigestInfo(crypto.SHA256, digest[:20]); err == nil {
+		t.Error("expected error for wrong digest length")
+	}
+
+	if _, err := EncodeDigestInfo(crypto.MD5, digest[:16]); err == nil {
+		t.Error("expected error for unsupported hash")
+	}
+}
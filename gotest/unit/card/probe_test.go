@@ -0,0 +1,157 @@
+package card
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ebfe/scard"
+	"github.com/stretchr/testify/mock"
+	testhelpers "github.com/ubavic/bas-celik/v2/test_helpers"
+)
+
+// T=1 card with "Bas Celik" as historical bytes
+var unknownAtr = Atr{0x3B, 0x89, 0x80, 0x01, 0x42, 0x61, 0x73, 0x20, 0x43, 0x65, 0x6C, 0x69, 0x6B, 0x30}
+
+func TestProbeCard(t *testing.T) {
+	secret := []byte{0xDE, 0xAD, 0xBE, 0xEF, 0x01, 0x02}
+
+	virtualCard := MakeVirtualCard(unknownAtr, map[uint32][]byte{
+		0x0F02: secret,
+		0x0F03: secret,
+	})
+	virtualCard.AddApplication([]byte{0xF3, 0x81, 0x00, 0x00, 0x02, 0x53, 0x45, 0x52, 0x49, 0x46, 0x01})
+
+	report, err := ProbeCard(virtualCard)
+	if err != nil {
+		t.Fatalf("ProbeCard() unexpected error: %v", err)
+	}
+
+	if !slices.Equal(report.Atr, HexBytes(unknownAtr)) || report.AtrInfo == nil {
+		t.Errorf("unexpected ATR in report %X", report.Atr)
+	}
+
+	if len(report.Drivers) != 0 {
+		t.Errorf("expected no drivers, got %v", report.Drivers)
+	}
+
+	if len(report.Applications) != len(probeApplications) {
+		t.Fatalf("expected %d applications, got %d", len(probeApplications), len(report.Applications))
+	}
+
+	selected := []string{}
+	for _, application := range report.Applications {
+		if application.Selected {
+			selected = append(selected, application.Name)
+		}
+
+		for _, attempt := range application.Attempts {
+			if ins := attempt.Command[1]; ins != 0xA4 && ins != 0xB0 {
+				t.Errorf("unsafe command %X sent", attempt.Command)
+			}
+		}
+	}
+
+	if !slices.Equal(selected, []string{"Apollo", "SERIF"}) {
+		t.Errorf("unexpected selected applications %v", selected)
+	}
+
+	serif := report.Applications[2]
+	expected := []struct {
+		description string
+		status      string
+		length      int
+	}{
+		{"select AID F381000002534552494601", "9000", 0},
+		{"select file 0F02", "9000", 0},
+		{"read file 0F02", "9000", 4},
+		{"select file 0F03", "9000", 0},
+		{"read file 0F03", "9000", 4},
+		{"select file 0F04", "6A82", 0},
+		{"select file 0F06", "6A82", 0},
+	}
+
+	if len(serif.Attempts) != len(expected) {
+		t.Fatalf("expected %d attempts, got %d", len(expected), len(serif.Attempts))
+	}
+
+	for i, attempt := range serif.Attempts {
+		if attempt.Description != expected[i].description || attempt.Status != expected[i].status || attempt.Length != expected[i].length {
+			t.Errorf("unexpected attempt %d: %+v", i, attempt)
+		}
+	}
+
+	if serif.Attempts[5].Error == "" {
+		t.Error("expected error description for missing file")
+	}
+
+	serid := report.Applications[1]
+	if len(serid.Attempts) != 1 || serid.Attempts[0].Status != "6A82" {
+		t.Errorf("expected only failed selection of SERID, got %+v", serid.Attempts)
+	}
+
+	data, err := report.BuildJson()
+	if err != nil {
+		t.Fatalf("BuildJson() unexpected error: %v", err)
+	}
+
+	if strings.Contains(strings.ToLower(string(data)), "deadbeef") {
+		t.Error("report contains file data")
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if decoded["atr"] != unknownAtr.String() {
+		t.Errorf("expected ATR %s in JSON, got %v", unknownAtr, decoded["atr"])
+	}
+}
+
+func TestProbeCard_KnownAtr(t *testing.T) {
+	report, err := ProbeCard(MakeVirtualCard(GEMALTO_ATR_4, map[uint32][]byte{}))
+	if err != nil {
+		t.Fatalf("ProbeCard() unexpected error: %v", err)
+	}
+
+	if !slices.Equal(report.Drivers, []string{"Gemalto", "Vehicle"}) {
+		t.Errorf("unexpected drivers %v", report.Drivers)
+	}
+}
+
+func TestProbeCard_TransmitError(t *testing.T) {
+	cm := &testhelpers.CardMock{}
+	cm.On("Status").Return(&scard.CardStatus{Atr: unknownAtr}, nil).Once()
+	cm.On("Transmit", mock.Anything).Return([]byte{0x6A, 0x82}, nil).Times(4)
+	cm.On("Transmit", mock.Anything).Return(nil, errors.New("card removed")).Once()
+
+	report, err := ProbeCard(cm)
+	if err == nil || !strings.Contains(err.Error(), "SERID") {
+		t.Fatalf("expected error while probing SERID, got %v", err)
+	}
+
+	if len(report.Applications) != 2 {
+		t.Fatalf("expected partial report with 2 applications, got %d", len(report.Applications))
+	}
+
+	last := report.Applications[1].Attempts[0]
+	if last.Error == "" || last.Status != "" {
+		t.Errorf("expected attempt with transmit error, got %+v", last)
+	}
+
+	cm.AssertExpectations(t)
+}
+
+func Test_sendProbeCommand_Unsafe(t *testing.T) {
+	cm := &testhelpers.CardMock{}
+
+	_, _, err := sendProbeCommand(cm, probeCommand{description: "verify", ins: 0x20, p2: 0x80})
+	if err == nil {
+		t.Fatal("expected error for unsafe instruction")
+	}
+
+	cm.AssertNotCalled(t, "Transmit", mock.Anything)
+}
@@ -0,0 +1,127 @@
+package card
+
+import (
+	"testing"
+)
+
+func TestProgress_Fraction(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress Progress
+		expected float64
+	}{
+		{"no files", Progress{}, 0},
+		{"first file started", Progress{FileCount: 4, BytesTotal: 100}, 0},
+		{"half of second file", Progress{FileIndex: 1, FileCount: 4, BytesRead: 50, BytesTotal: 100}, 0.375},
+		{"empty last file", Progress{FileIndex: 3, FileCount: 4}, 1},
+		{"last file read", Progress{FileIndex: 3, FileCount: 4, BytesRead: 10, BytesTotal: 10}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.progress.Fraction(); got != tt.expected {
+				t.Errorf("Fraction() = %f, expected %f", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGemalto_ReadCardProgress(t *testing.T) {
+	file := []byte{0x00, 0x00, 0x03, 0x00, 0x01, 0x02, 0x03}
+
+	// Portrait is read in three parts
+	photoLength := 600
+	photo := append([]byte{0x00, 0x00, byte(photoLength & 0xFF), byte(photoLength >> 8)}, make([]byte, photoLength)...)
+
+	virtualCard := MakeVirtualCard(GEMALTO_ATR_3, map[uint32][]byte{
+		0x0F02: file,
+		0x0F03: file,
+		0x0F04: file,
+		0x0F06: photo,
+	})
+
+	gemalto := Gemalto{atr: GEMALTO_ATR_3, smartCard: virtualCard}
+
+	updates := make([]Progress, 0)
+	gemalto.SetProgress(func(progress Progress) {
+		updates = append(updates, progress)
+	})
+
+	if err := gemalto.ReadCard(); err != nil {
+		t.Fatalf("ReadCard() unexpected error: %v", err)
+	}
+
+	// Each file reports its size and every part read
+	if len(updates) != 2+2+2+4 {
+		t.Fatalf("expected 10 updates, got %d: %+v", len(updates), updates)
+	}
+
+	files := []string{}
+	previous := 0.0
+	for _, update := range updates {
+		if update.FileCount != 4 {
+			t.Errorf("expected 4 files, got %d", update.FileCount)
+		}
+
+		if len(files) == 0 || files[len(files)-1] != update.File {
+			files = append(files, update.File)
+		}
+
+		if update.Fraction() < previous {
+			t.Errorf("progress decreased from %f to %f", previous, update.Fraction())
+		}
+		previous = update.Fraction()
+	}
+
+	expectedFiles := []string{"0f02", "0f03", "0f04", "0f06"}
+	if len(files) != len(expectedFiles) {
+		t.Fatalf("expected files %v, got %v", expectedFiles, files)
+	}
+
+	for i := range files {
+		if files[i] != expectedFiles[i] {
+			t.Errorf("expected file %s, got %s", expectedFiles[i], files[i])
+		}
+	}
+
+	last := updates[len(updates)-1]
+	if last.FileIndex != 3 || last.BytesRead != uint(photoLength) || last.BytesTotal != uint(photoLength) || last.Fraction() != 1 {
+		t.Errorf("unexpected last update %+v", last)
+	}
+
+	// Files read outside ReadCard are not reported
+	count := len(updates)
+	if _, err := gemalto.ReadFile(ID_DOCUMENT_FILE_LOC); err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+
+	if len(updates) != count {
+		t.Errorf("expected no updates outside ReadCard, got %+v", updates[count:])
+	}
+}
+
+func TestMedicalCard_ReadCardProgress(t *testing.T) {
+	file := []byte{0x00, 0x00, 0x02, 0x00, 0x01, 0x02}
+
+	virtualCard := MakeVirtualCard(MEDICAL_ATR_2, map[uint32][]byte{
+		0x0D01: file,
+		0x0D02: file,
+		0x0D03: file,
+		0x0D04: file,
+	})
+
+	medical := MedicalCard{atr: MEDICAL_ATR_2, smartCard: virtualCard}
+
+	var last Progress
+	medical.SetProgress(func(progress Progress) {
+		last = progress
+	})
+
+	if err := medical.ReadCard(); err != nil {
+		t.Fatalf("ReadCard() unexpected error: %v", err)
+	}
+
+	if last.File != "0d04" || last.Fraction() != 1 {
+		t.Errorf("unexpected last update %+v", last)
+	}
+}
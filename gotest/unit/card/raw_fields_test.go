@@ -0,0 +1,150 @@
+package card
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/ubavic/bas-celik/v2/document"
+)
+
+func utf16Bytes(s string) []byte {
+	out := make([]byte, 0)
+	for _, r := range utf16.Encode([]rune(s)) {
+		out = append(out, byte(r), byte(r>>8))
+	}
+	return out
+}
+
+func TestParseIDDocumentFile_RawFields(t *testing.T) {
+	data := slices.Concat(
+		makeTLV(1700, []byte("нова вредност")),
+		makeTLV(1546, []byte("REG")),
+		makeTLV(1690, []byte{0x00, 0x01, 0xFF}),
+	)
+
+	doc := document.IDDocument{}
+	if err := parseIDDocumentFile(data, &doc); err != nil {
+		t.Fatalf("parseIDDocumentFile() unexpected error: %v", err)
+	}
+
+	if doc.DocRegNo != "REG" {
+		t.Errorf("expected known field to be assigned, got %q", doc.DocRegNo)
+	}
+
+	expected := []document.RawField{
+		{File: "0f02", Tag: 1690, Value: []byte{0x00, 0x01, 0xFF}},
+		{File: "0f02", Tag: 1700, Value: []byte("нова вредност"), Text: "нова вредност"},
+	}
+
+	if len(doc.RawFields) != len(expected) {
+		t.Fatalf("expected %d raw fields, got %+v", len(expected), doc.RawFields)
+	}
+
+	for i := range expected {
+		got := doc.RawFields[i]
+		if got.File != expected[i].File || got.Tag != expected[i].Tag || !bytes.Equal(got.Value, expected[i].Value) || got.Text != expected[i].Text {
+			t.Errorf("raw field %d: expected %+v, got %+v", i, expected[i], got)
+		}
+	}
+}
+
+func TestParseIDFiles_NoRawFields(t *testing.T) {
+	doc := document.IDDocument{}
+
+	if err := parseIDPersonalFile(makeTLV(1558, []byte("0101990710006")), &doc); err != nil {
+		t.Fatalf("parseIDPersonalFile() unexpected error: %v", err)
+	}
+
+	if err := parseIDResidenceFile(makeTLV(1568, []byte("SRB")), &doc); err != nil {
+		t.Fatalf("parseIDResidenceFile() unexpected error: %v", err)
+	}
+
+	if doc.RawFields != nil {
+		t.Errorf("expected no raw fields, got %+v", doc.RawFields)
+	}
+}
+
+func TestParseMedicalFiles_RawFields(t *testing.T) {
+	doc := document.MedicalDocument{}
+
+	admin := slices.Concat(
+		makeTLV(1604, []byte("JMBG")),
+		makeTLV(1641, []byte("01012024")),
+		makeTLV(1640, utf16Bytes("Ђурђевдан")),
+	)
+
+	if err := parseMedicalVariableAdminFile(admin, &doc); err != nil {
+		t.Fatalf("parseMedicalVariableAdminFile() unexpected error: %v", err)
+	}
+
+	if err := parseMedicalVariablePersonalFile(makeTLV(1590, utf16Bytes("Latin")), &doc); err != nil {
+		t.Fatalf("parseMedicalVariablePersonalFile() unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		file string
+		tag  uint
+		text string
+	}{
+		{"0d04", 1640, "Ђурђевдан"},
+		{"0d04", 1641, "01012024"},
+		{"0d03", 1590, "Latin"},
+	}
+
+	if len(doc.RawFields) != len(expected) {
+		t.Fatalf("expected %d raw fields, got %+v", len(expected), doc.RawFields)
+	}
+
+	for i, field := range expected {
+		got := doc.RawFields[i]
+		if got.File != field.file || got.Tag != field.tag || got.Text != field.text {
+			t.Errorf("raw field %d: expected %+v, got %+v", i, field, got)
+		}
+	}
+
+	if !bytes.Equal(doc.RawFields[0].Value, utf16Bytes("Ђурђевдан")) {
+		t.Errorf("expected raw value to be kept, got %X", doc.RawFields[0].Value)
+	}
+}
+
+func TestMedicalDocument_RawFieldsJSON(t *testing.T) {
+	doc := document.MedicalDocument{}
+
+	data, err := doc.BuildJson()
+	if err != nil {
+		t.Fatalf("BuildJson() unexpected error: %v", err)
+	}
+
+	if strings.Contains(string(data), "RawFields") {
+		t.Errorf("expected no raw fields in JSON, got %s", data)
+	}
+
+	doc.RawFields = []document.RawField{{File: "0d01", Tag: 1561, Value: []byte{0x01, 0x02}}}
+
+	data, err = doc.BuildJson()
+	if err != nil {
+		t.Fatalf("BuildJson() unexpected error: %v", err)
+	}
+
+	var decoded struct {
+		RawFields []map[string]any
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshaling JSON: %v", err)
+	}
+
+	expected := map[string]any{"File": "0d01", "Tag": float64(1561), "Value": "AQI="}
+	if len(decoded.RawFields) != 1 || len(decoded.RawFields[0]) != len(expected) {
+		t.Fatalf("unexpected raw fields in JSON: %s", data)
+	}
+
+	for key, value := range expected {
+		if decoded.RawFields[0][key] != value {
+			t.Errorf("expected %s = %v, got %v", key, value, decoded.RawFields[0][key])
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package card
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ebfe/scard"
+	"github.com/stretchr/testify/mock"
+	testhelpers "github.com/ubavic/bas-celik/v2/test_helpers"
+)
+
+func TestDriversByAtr(t *testing.T) {
+	tests := []struct {
+		name     string
+		atr      Atr
+		expected []CardDocumentType
+	}{
+		{"apollo", APOLLO_ATR, []CardDocumentType{ApolloIDDocumentCardType}},
+		{"gemalto 1", GEMALTO_ATR_1, []CardDocumentType{GemaltoIDDocumentCardType, VehicleDocumentCardType}},
+		{"gemalto 2", GEMALTO_ATR_2, []CardDocumentType{GemaltoIDDocumentCardType, MedicalDocumentCardType, VehicleDocumentCardType}},
+		{"gemalto 3", GEMALTO_ATR_3, []CardDocumentType{GemaltoIDDocumentCardType, MedicalDocumentCardType, VehicleDocumentCardType}},
+		{"gemalto 4", GEMALTO_ATR_4, []CardDocumentType{GemaltoIDDocumentCardType, VehicleDocumentCardType}},
+		{"medical 1", MEDICAL_ATR_1, []CardDocumentType{MedicalDocumentCardType}},
+		{"medical 2", MEDICAL_ATR_2, []CardDocumentType{MedicalDocumentCardType}},
+		{"vehicle 0", VEHICLE_ATR_0, []CardDocumentType{VehicleDocumentCardType}},
+		{"vehicle 2", VEHICLE_ATR_2, []CardDocumentType{VehicleDocumentCardType}},
+		{"vehicle 3", VEHICLE_ATR_3, []CardDocumentType{VehicleDocumentCardType}},
+		{"vehicle 4", VEHICLE_ATR_4, []CardDocumentType{VehicleDocumentCardType}},
+		{"unknown", Atr{0x01, 0x02}, []CardDocumentType{UnknownDocumentCardType}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectCardDocumentByAtr(tt.atr)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("DetectCardDocumentByAtr(%s) = %v, expected %v", tt.atr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDriversOrder(t *testing.T) {
+	registered := Drivers()
+	if len(registered) != 4 {
+		t.Fatalf("expected 4 registered drivers, got %d", len(registered))
+	}
+
+	for i := 1; i < len(registered); i++ {
+		if registered[i-1].Priority > registered[i].Priority {
+			t.Errorf("drivers not ordered by priority: %s before %s", registered[i-1].Name, registered[i].Name)
+		}
+	}
+}
+
+func TestRegisterDriver(t *testing.T) {
+	saved := Drivers()
+	t.Cleanup(func() { drivers = saved })
+
+	newAtr := Atr{0x3B, 0x01, 0x02, 0x03}
+
+	RegisterDriver(Driver{
+		Type:     VehicleDocumentCardType,
+		Name:     "New vehicle",
+		Atrs:     []Atr{newAtr},
+		Priority: 5,
+		New: func(atr Atr, smartCard Card) CardDocument {
+			return &VehicleCard{atr: atr, smartCard: smartCard}
+		},
+		Probe: probeByTest,
+	})
+
+	if Drivers()[1].Name != "New vehicle" {
+		t.Errorf("expected new driver after Apollo, got %s", Drivers()[1].Name)
+	}
+
+	cm := &testhelpers.CardMock{}
+	cm.On("Status").Return(&scard.CardStatus{Atr: newAtr}, nil).Once()
+	cm.On("Transmit", mock.Anything).Return([]byte{0x90, 0x00}, nil).Times(3)
+
+	doc, err := DetectCardDocument(cm)
+	if err != nil {
+		t.Fatalf("DetectCardDocument() unexpected error: %v", err)
+	}
+
+	if _, ok := doc.(*VehicleCard); !ok {
+		t.Fatalf("expected Vehicle card, got %T", doc)
+	}
+
+	if !doc.Atr().Is(newAtr) {
+		t.Errorf("expected ATR %s, got %s", newAtr, doc.Atr())
+	}
+
+	cm.AssertExpectations(t)
+}
+
+func TestDetectCardDocument_ProbeFallback(t *testing.T) {
+	cm := &testhelpers.CardMock{}
+	cm.On("Status").Return(&scard.CardStatus{Atr: GEMALTO_ATR_4}, nil).Once()
+	// Gemalto InitCard fails for all three applets
+	cm.On("Transmit", mock.Anything).Return([]byte{0x6A, 0x82}, nil).Times(3)
+	// Vehicle InitCard succeeds
+	cm.On("Transmit", mock.Anything).Return([]byte{0x90, 0x00}, nil).Times(3)
+
+	doc, err := DetectCardDocument(cm)
+	if err != nil {
+		t.Fatalf("DetectCardDocument() unexpected error: %v", err)
+	}
+
+	if _, ok := doc.(*VehicleCard); !ok {
+		t.Fatalf("expected Vehicle card, got %T", doc)
+	}
+
+	cm.AssertExpectations(t)
+}
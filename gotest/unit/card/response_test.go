@@ -0,0 +1,185 @@
+package card
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/ubavic/bas-celik/v2/card/carderrors"
+	testhelpers "github.com/ubavic/bas-celik/v2/test_helpers"
+)
+
+func Test_ParseResponseAPDU(t *testing.T) {
+	testCases := []struct {
+		value  []byte
+		ok     bool
+		data   []byte
+		sw     uint16
+		hasErr bool
+	}{
+		{value: []byte{0x0F, 0x0F}, ok: false, data: []byte{}, sw: 0x0F0F},
+		{value: []byte{0x90, 0x00}, ok: true, data: []byte{}, sw: 0x9000},
+		{value: []byte{0x01, 0xFF, 0x90, 0x00}, ok: true, data: []byte{0x01, 0xFF}, sw: 0x9000},
+		{value: []byte{0x01, 0xFF, 0x00, 0x00}, ok: false, data: []byte{0x01, 0xFF}, sw: 0x0000},
+		{value: []byte{0xA1}, hasErr: true},
+	}
+
+	for i, testCase := range testCases {
+		t.Run(
+			fmt.Sprintf("Case %d", i),
+			func(t *testing.T) {
+				rsp, err := ParseResponseAPDU(testCase.value)
+				if testCase.hasErr {
+					if !errors.Is(err, carderrors.ErrInvalidLength) {
+						t.Fatalf("Expected ErrInvalidLength, but got %v", err)
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if rsp.OK() != testCase.ok {
+					t.Errorf("Expected OK %t, but got %t", testCase.ok, rsp.OK())
+				}
+
+				if string(rsp.Data) != string(testCase.data) {
+					t.Errorf("Expected data %X, but got %X", testCase.data, rsp.Data)
+				}
+
+				if rsp.SW() != testCase.sw {
+					t.Errorf("Expected SW %04X, but got %04X", testCase.sw, rsp.SW())
+				}
+
+				if string(rsp.Bytes()) != string(testCase.value) {
+					t.Errorf("Expected bytes %X, but got %X", testCase.value, rsp.Bytes())
+				}
+			},
+		)
+	}
+}
+
+func TestResponseAPDU_Err(t *testing.T) {
+	testCases := []struct {
+		sw1, sw2 byte
+		expected error
+	}{
+		{0x62, 0x82, carderrors.ErrEndOfFile},
+		{0x63, 0xC2, carderrors.ErrVerificationFailed},
+		{0x67, 0x00, carderrors.ErrWrongLength},
+		{0x69, 0x82, carderrors.ErrSecurityStatusNotSatisfied},
+		{0x69, 0x83, carderrors.ErrAuthenticationBlocked},
+		{0x69, 0x85, carderrors.ErrConditionsNotSatisfied},
+		{0x69, 0x86, carderrors.ErrCommandNotAllowed},
+		{0x6A, 0x80, carderrors.ErrWrongData},
+		{0x6A, 0x81, carderrors.ErrFunctionNotSupported},
+		{0x6A, 0x82, carderrors.ErrFileNotFound},
+		{0x6A, 0x83, carderrors.ErrRecordNotFound},
+		{0x6A, 0x86, carderrors.ErrIncorrectParameters},
+		{0x6B, 0x00, carderrors.ErrIncorrectParameters},
+		{0x6C, 0x10, carderrors.ErrWrongLe},
+		{0x6D, 0x00, carderrors.ErrInstructionNotSupported},
+		{0x6E, 0x00, carderrors.ErrClassNotSupported},
+		{0x6F, 0x00, carderrors.ErrUnknownStatus},
+		{0x62, 0x83, carderrors.ErrUnknownStatus},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprintf("%02X%02X", testCase.sw1, testCase.sw2), func(t *testing.T) {
+			err := ResponseAPDU{SW1: testCase.sw1, SW2: testCase.sw2}.Err()
+			if !errors.Is(err, testCase.expected) {
+				t.Fatalf("Expected %v, but got %v", testCase.expected, err)
+			}
+
+			var statusErr *carderrors.StatusError
+			if !errors.As(err, &statusErr) || statusErr.SW1 != testCase.sw1 || statusErr.SW2 != testCase.sw2 {
+				t.Errorf("Expected StatusError with %02X%02X, but got %v", testCase.sw1, testCase.sw2, err)
+			}
+		})
+	}
+
+	if err := (ResponseAPDU{SW1: 0x90, SW2: 0x00}).Err(); err != nil {
+		t.Errorf("Expected nil for 9000, but got %v", err)
+	}
+}
+
+func TestPinTriesLeft_StatusWord(t *testing.T) {
+	testCases := []struct {
+		rsp      []byte
+		expected int
+	}{
+		{[]byte{0x63, 0xC0}, 0},
+		{[]byte{0x63, 0xC3}, 3},
+		{[]byte{0x69, 0x83}, 0},
+		{[]byte{0x01, 0x02, 0x63, 0xC2}, 2},
+		{[]byte{0x63, 0xC4}, -1},
+		{[]byte{0x90, 0x00}, -1},
+		{[]byte{0x63}, -1},
+	}
+
+	for _, testCase := range testCases {
+		if triesLeft := PinTriesLeft(testCase.rsp); triesLeft != testCase.expected {
+			t.Errorf("%X: expected %d, but got %d", testCase.rsp, testCase.expected, triesLeft)
+		}
+	}
+}
+
+func TestDriverStatusErrors(t *testing.T) {
+	t.Run("gemalto file not found", func(t *testing.T) {
+		cm := &testhelpers.CardMock{}
+		cm.On("Transmit", mock.Anything).Return([]byte{0x6A, 0x82}, nil).Once()
+
+		g := Gemalto{smartCard: cm}
+		_, err := g.ReadFile(ID_DOCUMENT_FILE_LOC)
+		if !errors.Is(err, carderrors.ErrFileNotFound) {
+			t.Errorf("Expected ErrFileNotFound, but got %v", err)
+		}
+	})
+
+	t.Run("gemalto unknown applet", func(t *testing.T) {
+		cm := &testhelpers.CardMock{}
+		cm.On("Transmit", mock.Anything).Return([]byte{0x6A, 0x82}, nil).Times(3)
+
+		g := Gemalto{smartCard: cm}
+		err := g.InitCard()
+		if !errors.Is(err, carderrors.ErrFileNotFound) {
+			t.Errorf("Expected ErrFileNotFound, but got %v", err)
+		}
+	})
+
+	t.Run("medical security status", func(t *testing.T) {
+		cm := &testhelpers.CardMock{}
+		cm.On("Transmit", mock.Anything).Return([]byte{0x69, 0x82}, nil).Once()
+
+		m := MedicalCard{smartCard: cm}
+		_, err := m.ReadFile(MED_DOCUMENT_FILE_LOC)
+		if !errors.Is(err, carderrors.ErrSecurityStatusNotSatisfied) {
+			t.Errorf("Expected ErrSecurityStatusNotSatisfied, but got %v", err)
+		}
+	})
+
+	t.Run("medical read status not checked", func(t *testing.T) {
+		cm := &testhelpers.CardMock{}
+		cm.On("Transmit", mock.Anything).Return([]byte{0x90, 0x00}, nil).Once()
+		cm.On("Transmit", mock.Anything).Return([]byte{0x69, 0x82}, nil).Once()
+
+		m := MedicalCard{smartCard: cm}
+		_, err := m.ReadFile(MED_DOCUMENT_FILE_LOC)
+		if err == nil || errors.Is(err, carderrors.ErrSecurityStatusNotSatisfied) {
+			t.Errorf("Expected error for the empty file header, but got %v", err)
+		}
+	})
+
+	t.Run("vehicle blocked", func(t *testing.T) {
+		cm := &testhelpers.CardMock{}
+		cm.On("Transmit", mock.Anything).Return([]byte{0x69, 0x83}, nil).Once()
+
+		v := VehicleCard{smartCard: cm}
+		_, err := v.ReadFile([]byte{0xD0, 0x01})
+		if !errors.Is(err, carderrors.ErrAuthenticationBlocked) {
+			t.Errorf("Expected ErrAuthenticationBlocked, but got %v", err)
+		}
+	})
+}
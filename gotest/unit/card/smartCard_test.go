@@ -53,13 +53,218 @@ func TestVirtualCard_Status(t *testing.T) {
 }
 
 func TestTransmit(t *testing.T) {
-	response, err := Transmit([]byte{0x00, 0xA4, 0x04, 0x00})
+	fs := map[uint32][]byte{
+		0x0F02: {0x01, 0x02, 0x03, 0x04, 0x05},
+	}
+
+	aid := []byte{0xF3, 0x81, 0x00, 0x00, 0x02, 0x53, 0x45, 0x52, 0x49, 0x44, 0x01}
+
+	tests := []struct {
+		name     string
+		apdus    [][]byte
+		expected []byte
+	}{
+		{name: "select AID", apdus: [][]byte{append([]byte{0x00, 0xA4, 0x04, 0x00, 0x0B}, aid...)}, expected: []byte{0x90, 0x00}},
+		{name: "select unknown AID", apdus: [][]byte{{0x00, 0xA4, 0x04, 0x00, 0x02, 0xA0, 0x00}}, expected: []byte{0x6A, 0x82}},
+		{name: "select file", apdus: [][]byte{{0x00, 0xA4, 0x08, 0x00, 0x02, 0x0F, 0x02}}, expected: []byte{0x90, 0x00}},
+		{name: "select file with Le", apdus: [][]byte{{0x00, 0xA4, 0x08, 0x00, 0x02, 0x0F, 0x02, 0x04}}, expected: []byte{0x90, 0x00}},
+		{name: "select unknown file", apdus: [][]byte{{0x00, 0xA4, 0x02, 0x04, 0x02, 0x0F, 0x03}}, expected: []byte{0x6A, 0x82}},
+		{name: "select without data", apdus: [][]byte{{0x00, 0xA4, 0x04, 0x00}}, expected: []byte{0x67, 0x00}},
+		{name: "read without selected file", apdus: [][]byte{{0x00, 0xB0, 0x00, 0x00, 0x02}}, expected: []byte{0x69, 0x86}},
+		{
+			name:     "read binary",
+			apdus:    [][]byte{{0x00, 0xA4, 0x08, 0x00, 0x02, 0x0F, 0x02}, {0x00, 0xB0, 0x00, 0x00, 0x02}},
+			expected: []byte{0x01, 0x02, 0x90, 0x00},
+		},
+		{
+			name:     "read binary with offset",
+			apdus:    [][]byte{{0x00, 0xA4, 0x08, 0x00, 0x02, 0x0F, 0x02}, {0x00, 0xB0, 0x00, 0x03, 0x02}},
+			expected: []byte{0x04, 0x05, 0x90, 0x00},
+		},
+		{
+			name:     "read binary wrong Le",
+			apdus:    [][]byte{{0x00, 0xA4, 0x08, 0x00, 0x02, 0x0F, 0x02}, {0x00, 0xB0, 0x00, 0x02, 0x10}},
+			expected: []byte{0x6C, 0x03},
+		},
+		{
+			name:     "read binary offset outside file",
+			apdus:    [][]byte{{0x00, 0xA4, 0x08, 0x00, 0x02, 0x0F, 0x02}, {0x00, 0xB0, 0x00, 0x05, 0x01}},
+			expected: []byte{0x6B, 0x00},
+		},
+		{
+			name:     "read binary extended Le",
+			apdus:    [][]byte{{0x00, 0xA4, 0x08, 0x00, 0x02, 0x0F, 0x02}, {0x00, 0xB0, 0x00, 0x01, 0x00, 0x00, 0x03}},
+			expected: []byte{0x02, 0x03, 0x04, 0x90, 0x00},
+		},
+		{
+			name:     "read binary extended Le past end of file",
+			apdus:    [][]byte{{0x00, 0xA4, 0x08, 0x00, 0x02, 0x0F, 0x02}, {0x00, 0xB0, 0x00, 0x03, 0x00, 0x01, 0x2C}},
+			expected: []byte{0x04, 0x05, 0x62, 0x82},
+		},
+		{
+			name:     "read binary malformed extended Le",
+			apdus:    [][]byte{{0x00, 0xA4, 0x08, 0x00, 0x02, 0x0F, 0x02}, {0x00, 0xB0, 0x00, 0x00, 0x01, 0x00, 0x03}},
+			expected: []byte{0x67, 0x00},
+		},
+		{name: "unsupported instruction", apdus: [][]byte{{0x00, 0xCA, 0x00, 0x00, 0x00}}, expected: []byte{0x6D, 0x00}},
+		{name: "unsupported class", apdus: [][]byte{{0x80, 0xA4, 0x04, 0x00}}, expected: []byte{0x6E, 0x00}},
+		{name: "too short", apdus: [][]byte{{0x00, 0xA4}}, expected: []byte{0x67, 0x00}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			virtualCard := MakeVirtualCard(nil, fs)
+			virtualCard.AddApplication(aid)
+
+			var response []byte
+			var err error
+			for _, apdu := range tt.apdus {
+				response, err = virtualCard.Transmit(apdu)
+				if err != nil {
+					t.Fatalf("Unexpected error from Transmit(): %v", err)
+				}
+			}
+
+			if !slices.Equal(response, tt.expected) {
+				t.Errorf("Response mismatch: expected %X, got %X", tt.expected, response)
+			}
+		})
+	}
+}
+
+func TestVirtualCard_ExtendedRead(t *testing.T) {
+	file := make([]byte, 600)
+	for i := range file {
+		file[i] = byte(i)
+	}
+
+	virtualCard := MakeVirtualCard(nil, map[uint32][]byte{0x0F02: file})
+
+	rsp, err := sendAPDU(virtualCard, 0x00, 0xA4, 0x08, 0x00, []byte{0x0F, 0x02}, 0)
+	if err != nil || !rsp.OK() {
+		t.Fatalf("SELECT failed: %v, %X", err, rsp)
+	}
+
+	rsp, err = sendAPDU(virtualCard, 0x00, 0xB0, 0x00, 0x00, nil, 500)
 	if err != nil {
-		t.Fatalf("Unexpected error from Transmit(): %v", err)
+		t.Fatalf("READ BINARY unexpected error: %v", err)
 	}
 
-	expectedResponse := []byte{0x90, 0x00}
-	if !slices.Equal(response, expectedResponse) {
-		t.Errorf("Response mismatch: expected %v, got %v", expectedResponse, response)
+	if !rsp.OK() || !slices.Equal(rsp.Data, file[:500]) {
+		t.Errorf("expected the first 500 bytes of the file, got %d bytes with SW %04X", len(rsp.Data), rsp.SW())
 	}
-}
\ No newline at end of file
+}
+
+func TestVirtualCard_Gemalto(t *testing.T) {
+	content := []byte{0x01, 0x02, 0x03}
+	file := append([]byte{0x00, 0x00, byte(len(content)), 0x00}, content...)
+	photo := append([]byte{0x00, 0x00, 0x08, 0x00}, []byte{0xAA, 0xAA, 0xAA, 0xAA, 0xFF, 0xD8, 0xFF, 0xD9}...)
+
+	virtualCard := MakeVirtualCard(GEMALTO_ATR_3, map[uint32][]byte{
+		0x0F02: file,
+		0x0F03: file,
+		0x0F04: file,
+		0x0F06: photo,
+	})
+	virtualCard.AddApplication([]byte{0xF3, 0x81, 0x00, 0x00, 0x02, 0x53, 0x45, 0x52, 0x49, 0x46, 0x01})
+
+	gemalto := Gemalto{atr: GEMALTO_ATR_3, smartCard: virtualCard}
+
+	if err := gemalto.InitCard(); err != nil {
+		t.Fatalf("InitCard() unexpected error: %v", err)
+	}
+
+	if err := gemalto.ReadCard(); err != nil {
+		t.Fatalf("ReadCard() unexpected error: %v", err)
+	}
+
+	if !slices.Equal(gemalto.documentFile, content) {
+		t.Errorf("document file mismatch: expected %X, got %X", content, gemalto.documentFile)
+	}
+
+	expectedPhoto := []byte{0xFF, 0xD8, 0xFF, 0xD9}
+	if !slices.Equal(gemalto.photoFile, expectedPhoto) {
+		t.Errorf("photo file mismatch: expected %X, got %X", expectedPhoto, gemalto.photoFile)
+	}
+}
+
+func TestVirtualCard_Apollo(t *testing.T) {
+	content := make([]byte, 300)
+	for i := range content {
+		content[i] = byte(i)
+	}
+	file := append([]byte{0x00, 0x00, 0x00, 0x00, 0x2C, 0x01}, content...)
+
+	virtualCard := MakeVirtualCard(APOLLO_ATR, map[uint32][]byte{0x0F02: file})
+	apollo := Apollo{atr: APOLLO_ATR, smartCard: virtualCard}
+
+	data, err := apollo.ReadFile(ID_DOCUMENT_FILE_LOC)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+
+	if !slices.Equal(data, content) {
+		t.Errorf("file mismatch: expected %X, got %X", content, data)
+	}
+
+	_, err = apollo.ReadFile(ID_PHOTO_FILE_LOC)
+	if err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestVirtualCard_Medical(t *testing.T) {
+	content := []byte{0x11, 0x22}
+	file := append([]byte{0x00, 0x00, byte(len(content)), 0x00}, content...)
+
+	virtualCard := MakeVirtualCard(GEMALTO_ATR_2, map[uint32][]byte{
+		0x0D01: file,
+		0x0D02: file,
+		0x0D03: file,
+		0x0D04: file,
+	})
+
+	medical := MedicalCard{atr: GEMALTO_ATR_2, smartCard: virtualCard}
+	if err := medical.InitCard(); err == nil {
+		t.Fatal("expected error before application is added")
+	}
+
+	virtualCard.AddApplication([]byte{0xF3, 0x81, 0x00, 0x00, 0x02, 0x53, 0x45, 0x52, 0x56, 0x53, 0x5A, 0x4B, 0x01})
+
+	if err := medical.InitCard(); err != nil {
+		t.Fatalf("InitCard() unexpected error: %v", err)
+	}
+
+	if err := medical.ReadCard(); err != nil {
+		t.Fatalf("ReadCard() unexpected error: %v", err)
+	}
+
+	if !slices.Equal(medical.variableAdminFile, content) {
+		t.Errorf("admin file mismatch: expected %X, got %X", content, medical.variableAdminFile)
+	}
+}
+
+func TestVirtualCard_Vehicle(t *testing.T) {
+	record := []byte{0x71, 0x03, 0x80, 0x01, 0x41}
+	file := append([]byte{0x00, 0x00}, record...)
+	for len(file) < 0x40 {
+		file = append(file, 0xFF)
+	}
+
+	virtualCard := MakeVirtualCard(VEHICLE_ATR_2, map[uint32][]byte{0xD001: file})
+	virtualCard.AddApplication([]byte{0xA0, 0x00, 0x00, 0x01, 0x51, 0x00, 0x00})
+
+	vehicle := VehicleCard{atr: VEHICLE_ATR_2, smartCard: virtualCard}
+	if !vehicle.Test() {
+		t.Fatal("Test() expected true")
+	}
+
+	data, err := vehicle.ReadFile([]byte{0xD0, 0x01})
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+
+	if !slices.Equal(data, record) {
+		t.Errorf("file mismatch: expected %X, got %X", record, data)
+	}
+}
@@ -0,0 +1,116 @@
+package tlv
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/ubavic/bas-celik/v2/card/carderrors"
+)
+
+func TestEncode_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[uint][]byte
+	}{
+		{"single field", map[uint][]byte{1546: []byte("123456789")}},
+		{"several fields", map[uint][]byte{
+			1546: []byte("123456789"),
+			1547: []byte("ID"),
+			1549: []byte("01.01.2020"),
+			1558: {0x31},
+		}},
+		{"empty values", map[uint][]byte{1: {}, 2: []byte("value"), 3: {}}},
+		{"largest tag", map[uint][]byte{0xFFFF: {0x01}}},
+		{"long value", map[uint][]byte{7: bytes.Repeat([]byte{0xAB}, 1000)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Encode(tt.fields)
+			if err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+
+			parsed, err := ParseTLV(data)
+			if err != nil {
+				t.Fatalf("ParseTLV() unexpected error: %v", err)
+			}
+
+			if !maps.EqualFunc(parsed, tt.fields, bytes.Equal) {
+				t.Errorf("expected %v, got %v", tt.fields, parsed)
+			}
+		})
+	}
+}
+
+func TestEncode_Format(t *testing.T) {
+	data, err := Encode(map[uint][]byte{0x0602: {0x41}, 0x0601: {0x42, 0x43}})
+	if err != nil {
+		t.Fatalf("Encode() unexpected error: %v", err)
+	}
+
+	// Tags are in ascending order, tags and lengths are little endian
+	expected := []byte{0x01, 0x06, 0x02, 0x00, 0x42, 0x43, 0x02, 0x06, 0x01, 0x00, 0x41}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("expected %X, got %X", expected, data)
+	}
+}
+
+func TestBuilder_ReproducesFile(t *testing.T) {
+	// Records of the file are not ordered by the tag
+	file := []byte{
+		0x0B, 0x06, 0x02, 0x00, 'I', 'D',
+		0x0A, 0x06, 0x03, 0x00, '1', '2', '3',
+		0x16, 0x06, 0x01, 0x00, 0x31,
+		0x17, 0x06, 0x01, 0x00, 0x30,
+	}
+
+	data, err := NewBuilder().
+		AddString(0x060B, "ID").
+		Add(0x060A, []byte("123")).
+		AddBool(0x0616, true).
+		AddBool(0x0617, false).
+		Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(data, file) {
+		t.Errorf("expected %X, got %X", file, data)
+	}
+
+	fields, err := ParseTLV(data)
+	if err != nil {
+		t.Fatalf("ParseTLV() unexpected error: %v", err)
+	}
+
+	var flag bool
+	AssignBoolField(fields, 0x0616, &flag)
+	if !flag {
+		t.Error("expected true boolean field")
+	}
+
+	AssignBoolField(fields, 0x0617, &flag)
+	if flag {
+		t.Error("expected false boolean field")
+	}
+}
+
+func TestBuilder_Errors(t *testing.T) {
+	_, err := NewBuilder().Add(0x10000, nil).Add(1, []byte{0x01}).Bytes()
+	if !errors.Is(err, carderrors.ErrInvalidLength) {
+		t.Errorf("expected invalid length error for large tag, got %v", err)
+	}
+
+	_, err = Encode(map[uint][]byte{1: make([]byte, 0x10000)})
+	if !errors.Is(err, carderrors.ErrInvalidLength) {
+		t.Errorf("expected invalid length error for long value, got %v", err)
+	}
+
+	data, err := NewBuilder().Bytes()
+	if err != nil || len(data) != 0 {
+		t.Errorf("expected empty data, got %X, %v", data, err)
+	}
+}
@@ -0,0 +1,34 @@
+package tlv
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRemainingTags(t *testing.T) {
+	fields := map[uint][]byte{
+		1700: {0x01},
+		1546: {0x02},
+		1690: {},
+		1547: {0x03},
+	}
+
+	tests := []struct {
+		name     string
+		known    []uint
+		expected []uint
+	}{
+		{"no known tags", nil, []uint{1546, 1547, 1690, 1700}},
+		{"some known tags", []uint{1546, 1547, 1999}, []uint{1690, 1700}},
+		{"all known tags", []uint{1700, 1690, 1547, 1546}, []uint{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RemainingTags(fields, tt.known...)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("RemainingTags() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package tlv
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/ubavic/bas-celik/v2/card/carderrors"
+)
+
+func TestParse(t *testing.T) {
+	record := []byte{0x0A, 0x06, 0x02, 0x00, 'I', 'D'}
+	other := []byte{0x0B, 0x06, 0x01, 0x00, 'X'}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		fields    map[uint][]byte
+		offset    uint
+		tag       uint
+		reason    error
+		wrapped   error
+		noWarning bool
+	}{
+		{
+			name:      "valid",
+			data:      append(bytes.Clone(record), other...),
+			fields:    map[uint][]byte{1546: []byte("ID"), 1547: []byte("X")},
+			noWarning: true,
+		},
+		{
+			name:    "truncated record",
+			data:    append(bytes.Clone(record), 0x0B, 0x06, 0x05, 0x00, 'X'),
+			fields:  map[uint][]byte{1546: []byte("ID")},
+			offset:  6,
+			tag:     1547,
+			reason:  ErrTruncatedRecord,
+			wrapped: carderrors.ErrInvalidLength,
+		},
+		{
+			name:    "duplicate tag",
+			data:    append(append(bytes.Clone(record), 0x0A, 0x06, 0x01, 0x00, 'Y'), other...),
+			fields:  map[uint][]byte{1546: []byte("Y"), 1547: []byte("X")},
+			offset:  6,
+			tag:     1546,
+			reason:  ErrDuplicateTag,
+			wrapped: carderrors.ErrInvalidFormat,
+		},
+		{
+			name:    "trailing bytes",
+			data:    append(bytes.Clone(record), 0x00, 0x00),
+			fields:  map[uint][]byte{1546: []byte("ID")},
+			offset:  6,
+			reason:  ErrTrailingBytes,
+			wrapped: carderrors.ErrInvalidLength,
+		},
+		{
+			name:    "empty",
+			data:    []byte{},
+			fields:  map[uint][]byte{},
+			reason:  carderrors.ErrInvalidLength,
+			wrapped: carderrors.ErrInvalidLength,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" strict", func(t *testing.T) {
+			fields, warnings, err := Parse(tt.data, Options{File: "0f02"})
+			if len(warnings) != 0 {
+				t.Errorf("Parse() unexpected warnings in strict mode: %v", warnings)
+			}
+
+			if tt.noWarning {
+				if err != nil || !maps.EqualFunc(fields, tt.fields, bytes.Equal) {
+					t.Errorf("Parse() = %v, %v, expected %v", fields, err, tt.fields)
+				}
+				return
+			}
+
+			if fields != nil {
+				t.Errorf("Parse() unexpected fields in strict mode: %v", fields)
+			}
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse() error = %v, expected *ParseError", err)
+			}
+
+			checkParseError(t, *parseErr, tt.offset, tt.tag, tt.reason, tt.wrapped)
+		})
+
+		t.Run(tt.name+" lenient", func(t *testing.T) {
+			fields, warnings, err := Parse(tt.data, Options{File: "0f02", Lenient: true})
+			if err != nil {
+				t.Fatalf("Parse() unexpected error in lenient mode: %v", err)
+			}
+
+			if !maps.EqualFunc(fields, tt.fields, bytes.Equal) {
+				t.Errorf("Parse() = %v, expected %v", fields, tt.fields)
+			}
+
+			if tt.noWarning {
+				if len(warnings) != 0 {
+					t.Errorf("Parse() unexpected warnings: %v", warnings)
+				}
+				return
+			}
+
+			if len(warnings) != 1 {
+				t.Fatalf("Parse() warnings = %v, expected one warning", warnings)
+			}
+
+			checkParseError(t, warnings[0], tt.offset, tt.tag, tt.reason, tt.wrapped)
+		})
+	}
+}
+
+func checkParseError(t *testing.T, parseErr ParseError, offset, tag uint, reason, wrapped error) {
+	t.Helper()
+
+	if parseErr.File != "0f02" || parseErr.Offset != offset || parseErr.Tag != tag || parseErr.Reason != reason {
+		t.Errorf("ParseError = %+v, expected offset %d, tag %d and reason %v", parseErr, offset, tag, reason)
+	}
+
+	if !errors.Is(&parseErr, wrapped) {
+		t.Errorf("ParseError %v doesn't wrap %v", &parseErr, wrapped)
+	}
+}
+
+func TestParseError_Error(t *testing.T) {
+	withFile := ParseError{File: "0f02", Offset: 6, Tag: 1547, Reason: ErrTruncatedRecord}
+	if got := withFile.Error(); got != "parsing TLV file 0f02 at offset 6, tag 1547: truncated record: invalid length" {
+		t.Errorf("Error() = %q", got)
+	}
+
+	withoutTag := ParseError{Offset: 6, Reason: ErrTrailingBytes}
+	if got := withoutTag.Error(); got != "parsing TLV at offset 6: trailing bytes: invalid length" {
+		t.Errorf("Error() = %q", got)
+	}
+}
@@ -0,0 +1,42 @@
+package card
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ubavic/bas-celik/v2/card/carderrors"
+	"github.com/ubavic/bas-celik/v2/card/tlv"
+	"github.com/ubavic/bas-celik/v2/document"
+)
+
+func TestParseIDPersonalFile_CorruptRecord(t *testing.T) {
+	// Surname, given name and a record with the length beyond the data
+	data := []byte{
+		0x17, 0x06, 0x04, 0x00, 'P', 'e', 'r', 'a',
+		0x18, 0x06, 0x03, 0x00, 'M', 'i', 'k',
+		0x19, 0x06, 0x10, 0x00, 'X',
+	}
+
+	doc := document.IDDocument{}
+	if err := parseIDPersonalFile(data, &doc); err != nil {
+		t.Fatalf("parseIDPersonalFile() unexpected error: %v", err)
+	}
+
+	if doc.Surname != "Pera" || doc.GivenName != "Mik" || doc.ParentGivenName != "" {
+		t.Errorf("unexpected fields %q, %q, %q", doc.Surname, doc.GivenName, doc.ParentGivenName)
+	}
+
+	if len(doc.Warnings) != 1 || !strings.Contains(doc.Warnings[0], "0f03") {
+		t.Errorf("expected one warning for file 0f03, got %q", doc.Warnings)
+	}
+}
+
+func TestParseTLVFile_NoFields(t *testing.T) {
+	_, _, err := parseTLVFile(ID_PERSONAL_FILE_LOC, []byte{0x17, 0x06, 0x10, 0x00, 'X'})
+
+	var parseErr *tlv.ParseError
+	if !errors.As(err, &parseErr) || parseErr.File != "0f03" || !errors.Is(err, carderrors.ErrInvalidLength) {
+		t.Errorf("expected parse error of file 0f03, got %v", err)
+	}
+}
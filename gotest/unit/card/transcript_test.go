@@ -0,0 +1,208 @@
+package card
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ebfe/scard"
+	"github.com/stretchr/testify/mock"
+	testhelpers "github.com/ubavic/bas-celik/v2/test_helpers"
+)
+
+func recordGemaltoSession(t *testing.T) []TranscriptEntry {
+	t.Helper()
+
+	cm := &testhelpers.CardMock{}
+	cm.On("Status").Return(&scard.CardStatus{
+		Atr:            GEMALTO_ATR_3,
+		Reader:         "Mock",
+		State:          scard.Specific,
+		ActiveProtocol: scard.ProtocolT1,
+	}, nil).Once()
+	cm.On("BeginTransaction").Return(nil).Once()
+	cm.On("Transmit", mock.Anything).Return([]byte{0x6A, 0x82}, nil).Once()
+	cm.On("Transmit", mock.Anything).Return([]byte{0x90, 0x00}, nil).Once()
+	cm.On("EndTransaction", scard.LeaveCard).Return(nil).Once()
+
+	rc := MakeRecordingCard(cm)
+
+	status, err := rc.Status()
+	if err != nil {
+		t.Fatalf("Status() unexpected error: %v", err)
+	}
+
+	if err := rc.BeginTransaction(); err != nil {
+		t.Fatalf("BeginTransaction() unexpected error: %v", err)
+	}
+
+	g := Gemalto{atr: status.Atr, smartCard: rc}
+	if err := g.InitCard(); err != nil {
+		t.Fatalf("InitCard() unexpected error: %v", err)
+	}
+
+	if err := rc.EndTransaction(scard.LeaveCard); err != nil {
+		t.Fatalf("EndTransaction() unexpected error: %v", err)
+	}
+
+	cm.AssertExpectations(t)
+
+	var buf bytes.Buffer
+	if err := rc.WriteTranscript(&buf); err != nil {
+		t.Fatalf("WriteTranscript() unexpected error: %v", err)
+	}
+
+	entries, err := ReadTranscript(&buf)
+	if err != nil {
+		t.Fatalf("ReadTranscript() unexpected error: %v", err)
+	}
+
+	return entries
+}
+
+func TestRecordingCard(t *testing.T) {
+	entries := recordGemaltoSession(t)
+
+	ops := []string{
+		TranscriptStatus,
+		TranscriptBeginTransaction,
+		TranscriptTransmit,
+		TranscriptTransmit,
+		TranscriptEndTransaction,
+	}
+
+	if len(entries) != len(ops) {
+		t.Fatalf("expected %d entries, got %d", len(ops), len(entries))
+	}
+
+	for i, op := range ops {
+		if entries[i].Op != op {
+			t.Errorf("entry %d: expected op %q, got %q", i, op, entries[i].Op)
+		}
+	}
+
+	if entries[0].Reader != "Mock" {
+		t.Errorf("expected reader Mock, got %q", entries[0].Reader)
+	}
+
+	if entries[2].Command != "00a404000bf381000002534552494401" {
+		t.Errorf("unexpected first command %q", entries[2].Command)
+	}
+
+	if entries[2].Response != "6a82" || entries[3].Response != "9000" {
+		t.Errorf("unexpected responses %q, %q", entries[2].Response, entries[3].Response)
+	}
+}
+
+func TestRecordingCardError(t *testing.T) {
+	cm := &testhelpers.CardMock{}
+	cm.On("Transmit", mock.Anything).Return(nil, errors.New("reader gone")).Once()
+
+	rc := MakeRecordingCard(cm)
+	_, err := rc.Transmit([]byte{0x00, 0xB0, 0x00, 0x00})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	replay := MakeReplayCard(rc.Transcript())
+	_, err = replay.Transmit([]byte{0x00, 0xB0, 0x00, 0x00})
+	if err == nil || err.Error() != "reader gone" {
+		t.Fatalf("expected recorded error, got %v", err)
+	}
+}
+
+func TestReplayCard(t *testing.T) {
+	replay := MakeReplayCard(recordGemaltoSession(t))
+
+	status, err := replay.Status()
+	if err != nil {
+		t.Fatalf("Status() unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(status.Atr, GEMALTO_ATR_3) {
+		t.Errorf("expected ATR %s, got %X", GEMALTO_ATR_3, status.Atr)
+	}
+
+	if status.Reader != "Mock" || status.State != scard.Specific || status.ActiveProtocol != scard.ProtocolT1 {
+		t.Errorf("expected recorded status, got %+v", status)
+	}
+
+	if err := replay.BeginTransaction(); err != nil {
+		t.Fatalf("BeginTransaction() unexpected error: %v", err)
+	}
+
+	g := Gemalto{atr: status.Atr, smartCard: replay}
+	if err := g.InitCard(); err != nil {
+		t.Fatalf("InitCard() unexpected error: %v", err)
+	}
+
+	if err := replay.EndTransaction(scard.LeaveCard); err != nil {
+		t.Fatalf("EndTransaction() unexpected error: %v", err)
+	}
+
+	if !replay.Done() {
+		t.Error("expected transcript to be fully replayed")
+	}
+
+	_, err = replay.Transmit([]byte{0x00})
+	if !errors.Is(err, ErrTranscriptEnd) {
+		t.Errorf("expected ErrTranscriptEnd, got %v", err)
+	}
+}
+
+func TestReplayCardMismatch(t *testing.T) {
+	entries := recordGemaltoSession(t)
+
+	t.Run("different command", func(t *testing.T) {
+		replay := MakeReplayCard(entries[2:])
+		_, err := replay.Transmit([]byte{0x00, 0xA4, 0x00, 0x00})
+		if !errors.Is(err, ErrTranscriptMismatch) {
+			t.Errorf("expected ErrTranscriptMismatch, got %v", err)
+		}
+	})
+
+	t.Run("different call", func(t *testing.T) {
+		replay := MakeReplayCard(entries)
+		err := replay.BeginTransaction()
+		if !errors.Is(err, ErrTranscriptMismatch) {
+			t.Errorf("expected ErrTranscriptMismatch, got %v", err)
+		}
+	})
+}
+
+func TestRecordingCard_WriteTranscriptFile(t *testing.T) {
+	cm := &testhelpers.CardMock{}
+	cm.On("Transmit", mock.Anything).Return([]byte{0x90, 0x00}, nil).Once()
+
+	rc := MakeRecordingCard(cm)
+	if _, err := rc.Transmit([]byte{0x00, 0xA4, 0x00, 0x00}); err != nil {
+		t.Fatalf("Transmit() unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "transcript.json")
+	if err := rc.WriteTranscriptFile(path); err != nil {
+		t.Fatalf("WriteTranscriptFile() unexpected error: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening transcript: %v", err)
+	}
+	defer file.Close()
+
+	entries, err := ReadTranscript(file)
+	if err != nil {
+		t.Fatalf("ReadTranscript() unexpected error: %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].Command != "00a40000" || entries[0].Response != "9000" {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+
+	info, err := file.Stat()
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected file readable only by the user, got %v, %v", info.Mode(), err)
+	}
+}
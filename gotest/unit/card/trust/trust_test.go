@@ -0,0 +1,240 @@
+package trust
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+type testCA struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	return key
+}
+
+// Issues a certificate. If issuer is nil, the certificate is self-signed.
+func issue(t *testing.T, issuer *testCA, template *x509.Certificate) testCA {
+	t.Helper()
+
+	key := newKey(t)
+	parent, signer := template, key
+	if issuer != nil {
+		parent, signer = issuer.certificate, issuer.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+
+	return testCA{certificate: certificate, key: key}
+}
+
+func caTemplate(serial int64, name string) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             now.AddDate(-5, 0, 0),
+		NotAfter:              now.AddDate(5, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+}
+
+func leafTemplate(serial int64, keyUsage x509.KeyUsage) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "Card holder"},
+		NotBefore:    now.AddDate(-1, 0, 0),
+		NotAfter:     now.AddDate(1, 0, 0),
+		KeyUsage:     keyUsage,
+	}
+}
+
+func newCRL(t *testing.T, issuer testCA, nextUpdate time.Time, revoked ...*big.Int) *x509.RevocationList {
+	t.Helper()
+
+	template := x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: now.AddDate(0, 0, -1),
+		NextUpdate: nextUpdate,
+	}
+
+	for _, serial := range revoked {
+		template.RevokedCertificateEntries = append(template.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   serial,
+			RevocationTime: now.AddDate(0, -1, 0),
+		})
+	}
+
+	der, err := x509.CreateRevocationList(rand.Reader, &template, issuer.certificate, issuer.key)
+	if err != nil {
+		t.Fatalf("creating CRL: %v", err)
+	}
+
+	crl, err := x509.ParseRevocationList(der)
+	if err != nil {
+		t.Fatalf("parsing CRL: %v", err)
+	}
+
+	return crl
+}
+
+func TestVerify(t *testing.T) {
+	root := issue(t, nil, caTemplate(1, "Root CA"))
+	intermediate := issue(t, &root, caTemplate(2, "Issuing CA"))
+	leaf := issue(t, &intermediate, leafTemplate(3, x509.KeyUsageDigitalSignature))
+	encryptionLeaf := issue(t, &intermediate, leafTemplate(4, x509.KeyUsageKeyEncipherment))
+	otherRoot := issue(t, nil, caTemplate(5, "Other CA"))
+
+	rootCRL := newCRL(t, root, now.AddDate(0, 1, 0))
+	intermediateCRL := newCRL(t, intermediate, now.AddDate(0, 0, 7))
+	revokingCRL := newCRL(t, intermediate, now.AddDate(0, 0, 7), big.NewInt(3))
+	staleCRL := newCRL(t, intermediate, now.AddDate(0, 0, -1))
+	forgedCRL := newCRL(t, otherRoot, now.AddDate(0, 0, 7))
+	forgedCRL.RawIssuer = intermediateCRL.RawIssuer
+
+	options := func(crls ...*x509.RevocationList) Options {
+		opts := Options{CRLs: crls, CurrentTime: now}
+		opts.AddTrusted([]*x509.Certificate{intermediate.certificate, root.certificate})
+		return opts
+	}
+
+	tests := []struct {
+		name        string
+		certificate *x509.Certificate
+		opts        Options
+		status      string
+		chain       int
+	}{
+		{"trusted", leaf.certificate, options(rootCRL, intermediateCRL), StatusTrusted, 3},
+		{"revoked", leaf.certificate, options(rootCRL, revokingCRL), StatusRevoked, 3},
+		{"missing CRL", leaf.certificate, options(intermediateCRL), StatusUnknown, 3},
+		{"stale CRL", leaf.certificate, options(rootCRL, staleCRL), StatusUnknown, 3},
+		{"forged CRL", leaf.certificate, options(rootCRL, forgedCRL), StatusUnknown, 3},
+		{"no roots", leaf.certificate, Options{CurrentTime: now}, StatusUnknown, 0},
+		{"other root", leaf.certificate, Options{Roots: []*x509.Certificate{otherRoot.certificate}, CurrentTime: now}, StatusUntrusted, 0},
+		{"missing intermediate", leaf.certificate, Options{Roots: []*x509.Certificate{root.certificate}, CurrentTime: now}, StatusUntrusted, 0},
+		{"not for signing", encryptionLeaf.certificate, options(rootCRL, intermediateCRL), StatusUntrusted, 0},
+		{"expired", leaf.certificate, Options{CurrentTime: now.AddDate(2, 0, 0)}, StatusExpired, 0},
+		{"not yet valid", leaf.certificate, Options{CurrentTime: now.AddDate(-2, 0, 0)}, StatusExpired, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Verify(tt.certificate, tt.opts)
+			if result.Status != tt.status {
+				t.Errorf("expected status %s, got %s (%s)", tt.status, result.Status, result.Reason)
+			}
+
+			if len(result.Chain) != tt.chain {
+				t.Errorf("expected chain of %d certificates, got %d", tt.chain, len(result.Chain))
+			}
+
+			if tt.status != StatusTrusted && result.Reason == "" {
+				t.Error("expected reason")
+			}
+		})
+	}
+}
+
+func TestOptions_AddTrusted(t *testing.T) {
+	root := issue(t, nil, caTemplate(1, "Root CA"))
+	intermediate := issue(t, &root, caTemplate(2, "Issuing CA"))
+
+	opts := Options{}
+	opts.AddTrusted([]*x509.Certificate{intermediate.certificate, root.certificate})
+
+	if len(opts.Roots) != 1 || opts.Roots[0] != root.certificate {
+		t.Errorf("expected root certificate in roots, got %d roots", len(opts.Roots))
+	}
+
+	if len(opts.Intermediates) != 1 || opts.Intermediates[0] != intermediate.certificate {
+		t.Errorf("expected issuing certificate in intermediates, got %d intermediates", len(opts.Intermediates))
+	}
+}
+
+func TestOptions_AddIntermediates(t *testing.T) {
+	root := issue(t, nil, caTemplate(1, "Root CA"))
+	leaf := issue(t, &root, leafTemplate(2, x509.KeyUsageDigitalSignature))
+
+	opts := Options{CurrentTime: now}
+	opts.AddIntermediates([]*x509.Certificate{root.certificate})
+
+	if len(opts.Roots) != 0 || len(opts.Intermediates) != 1 {
+		t.Fatalf("expected only an intermediate, got %d roots and %d intermediates", len(opts.Roots), len(opts.Intermediates))
+	}
+
+	// A self-signed certificate from an untrusted source doesn't make the chain trusted
+	if result := Verify(leaf.certificate, opts); result.Status == StatusTrusted || len(result.Chain) > 0 {
+		t.Errorf("expected untrusted certificate, got %s", result.Status)
+	}
+}
+
+func TestLoadCertificatesAndCRLs(t *testing.T) {
+	root := issue(t, nil, caTemplate(1, "Root CA"))
+	intermediate := issue(t, &root, caTemplate(2, "Issuing CA"))
+	crl := newCRL(t, root, now.AddDate(0, 1, 0))
+
+	fsys := fstest.MapFS{
+		"roots/root.pem":    {Data: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.certificate.Raw})},
+		"roots/issuing.cer": {Data: intermediate.certificate.Raw},
+		"roots/readme.txt":  {Data: []byte("ignored")},
+		"crl/root.crl":      {Data: crl.Raw},
+		"crl/root.pem":      {Data: pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl.Raw})},
+		"broken/broken.crt": {Data: []byte{0x30, 0x00}},
+	}
+
+	certificates, err := LoadCertificates(fsys, "roots")
+	if err != nil {
+		t.Fatalf("LoadCertificates() unexpected error: %v", err)
+	}
+
+	if len(certificates) != 2 {
+		t.Errorf("expected 2 certificates, got %d", len(certificates))
+	}
+
+	crls, err := LoadCRLs(fsys, "crl")
+	if err != nil {
+		t.Fatalf("LoadCRLs() unexpected error: %v", err)
+	}
+
+	if len(crls) != 2 {
+		t.Errorf("expected 2 CRLs, got %d", len(crls))
+	}
+
+	certificates, err = LoadCertificates(fsys, "missing")
+	if err != nil || len(certificates) != 0 {
+		t.Errorf("expected no certificates and no error for missing directory, got %d, %v", len(certificates), err)
+	}
+
+	_, err = LoadCertificates(fsys, "broken")
+	if err == nil {
+		t.Error("expected error for invalid certificate")
+	}
+}
@@ -0,0 +1,115 @@
+package card
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ubavic/bas-celik/v2/document"
+)
+
+func berNode(tag byte, value []byte) []byte {
+	return append([]byte{tag, byte(len(value))}, value...)
+}
+
+func TestVehicleCardGetDocument_Extra(t *testing.T) {
+	owner := slices.Concat(berNode(0x83, []byte("Petrović")), berNode(0x86, []byte("restriction")))
+	file0 := berNode(0x71, slices.Concat(
+		berNode(0x81, []byte("BG123AB")),
+		berNode(0xA1, berNode(0xA2, owner)),
+		berNode(0x97, []byte{0x00, 0xFF}),
+	))
+	file1 := berNode(0x72, slices.Concat(berNode(0x98, []byte("M1")), berNode(0xCA, []byte("BG999ZZ"))))
+
+	card := VehicleCard{files: [4][]byte{file0, file1, berNode(0x72, berNode(0x98, []byte("M1"))), file1}}
+
+	doc, err := card.GetDocument()
+	if err != nil {
+		t.Fatalf("GetDocument() unexpected error: %v", err)
+	}
+
+	vehicle := doc.(*document.VehicleDocument)
+	if vehicle.RegistrationNumberOfVehicle != "BG123AB" || vehicle.OwnersSurnameOrBusinessName != "Petrović" {
+		t.Fatalf("expected known fields to be assigned, got %+v", vehicle)
+	}
+
+	expected := []document.ExtraField{
+		{Address: "71/97", Value: []byte{0x00, 0xFF}},
+		{Address: "71/A1/A2/86", Value: []byte("restriction"), Text: "restriction"},
+		{Address: "72/CA", Value: []byte("BG999ZZ"), Text: "BG999ZZ"},
+	}
+
+	if len(vehicle.Extra) != len(expected) {
+		t.Fatalf("expected %d extra fields, got %+v", len(expected), vehicle.Extra)
+	}
+
+	for i := range expected {
+		got := vehicle.Extra[i]
+		if got.Address != expected[i].Address || string(got.Value) != string(expected[i].Value) || got.Text != expected[i].Text {
+			t.Errorf("extra field %d = %+v, want %+v", i, got, expected[i])
+		}
+	}
+
+	data, err := vehicle.BuildJson()
+	if err != nil {
+		t.Fatalf("BuildJson() unexpected error: %v", err)
+	}
+
+	if !strings.Contains(string(data), `{"Address":"71/A1/A2/86","Value":"cmVzdHJpY3Rpb24=","Text":"restriction"}`) {
+		t.Errorf("expected extra field in JSON, got %s", data)
+	}
+}
+
+func TestVehicleCardGetDocument_RepeatedRecords(t *testing.T) {
+	user := func(surname, name string) []byte {
+		return berNode(0xA9, slices.Concat(berNode(0x83, []byte(surname)), berNode(0x84, []byte(name))))
+	}
+
+	file0 := berNode(0x71, slices.Concat(
+		berNode(0xA1, slices.Concat(user("Petrović", "Petar"), user("Jovanović", "Jovan"))),
+		berNode(0xA1, user("Marković", "Marko")),
+	))
+	empty := berNode(0x72, nil)
+
+	card := VehicleCard{files: [4][]byte{file0, empty, empty, empty}}
+
+	doc, err := card.GetDocument()
+	if err != nil {
+		t.Fatalf("GetDocument() unexpected error: %v", err)
+	}
+
+	vehicle := doc.(*document.VehicleDocument)
+	if vehicle.UsersSurnameOrBusinessName != "Petrović" || vehicle.UsersName != "Petar" {
+		t.Fatalf("expected the first user to be assigned, got %+v", vehicle)
+	}
+
+	expected := []document.ExtraField{
+		{Address: "71/A1/A9[1]/83", Text: "Jovanović"},
+		{Address: "71/A1/A9[1]/84", Text: "Jovan"},
+		{Address: "71/A1[1]/A9/83", Text: "Marković"},
+		{Address: "71/A1[1]/A9/84", Text: "Marko"},
+	}
+
+	if len(vehicle.Extra) != len(expected) {
+		t.Fatalf("expected %d extra fields, got %+v", len(expected), vehicle.Extra)
+	}
+
+	for i := range expected {
+		got := vehicle.Extra[i]
+		if got.Address != expected[i].Address || got.Text != expected[i].Text {
+			t.Errorf("extra field %d = %+v, want %+v", i, got, expected[i])
+		}
+	}
+}
+
+func TestVehicleDocument_NoExtraJSON(t *testing.T) {
+	data, err := json.Marshal(&document.VehicleDocument{})
+	if err != nil {
+		t.Fatalf("marshaling: %v", err)
+	}
+
+	if strings.Contains(string(data), "Extra") {
+		t.Errorf("expected no extra fields in JSON, got %s", data)
+	}
+}
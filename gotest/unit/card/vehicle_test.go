@@ -6,7 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/mock"
-	carderrors "github.com/ubavic/bas-celik/v2/card/cardErrors"
+	carderrors "github.com/ubavic/bas-celik/v2/card/carderrors"
 	"github.com/ubavic/bas-celik/v2/document"
 	testhelpers "github.com/ubavic/bas-celik/v2/test_helpers"
 )
@@ -204,7 +204,7 @@ func TestVehicleCardGetDocument(t *testing.T) {
 	inner0 := []byte{}
 	inner0 = append(inner0, makePrimitive(0x81, []byte("BG123AB"))...)          // RegistrationNumberOfVehicle
 	inner0 = append(inner0, makePrimitive(0x82, []byte("20200101"))...)         // DateOfFirstRegistration
-	inner0 = append(inner0, makePrimitive(0x8A, []byte("VIN123456789"))...)     // VehicleIdNumber
+	inner0 = append(inner0, makePrimitive(0x8A, []byte("VIN123456789"))...)     // VehicleIDNumber
 	inner0 = append(inner0, makePrimitive(0x8D, []byte("20251231"))...)         // ExpiryDate
 	inner0 = append(inner0, makePrimitive(0x8E, []byte("20200115"))...)         // IssuingDate
 	file0 := makeConstructed(0x71, inner0)
@@ -237,8 +237,8 @@ func TestVehicleCardGetDocument(t *testing.T) {
 				if doc.RegistrationNumberOfVehicle != "BG123AB" {
 					t.Fatalf("expected RegistrationNumberOfVehicle=BG123AB, got %s", doc.RegistrationNumberOfVehicle)
 				}
-				if doc.VehicleIdNumber != "VIN123456789" {
-					t.Fatalf("expected VehicleIdNumber=VIN123456789, got %s", doc.VehicleIdNumber)
+				if doc.VehicleIDNumber != "VIN123456789" {
+					t.Fatalf("expected VehicleIDNumber=VIN123456789, got %s", doc.VehicleIDNumber)
 				}
 				if doc.VehicleCategory != "M1" {
 					t.Fatalf("expected VehicleCategory=M1, got %s", doc.VehicleCategory)
@@ -0,0 +1,368 @@
+package card
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ubavic/bas-celik/v2/card/trust"
+	"github.com/ubavic/bas-celik/v2/document"
+)
+
+var oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+
+type testSigner struct {
+	key         *rsa.PrivateKey
+	certificate *x509.Certificate
+}
+
+func newTestSigner(t *testing.T) testSigner {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Document signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+
+	return testSigner{key: key, certificate: certificate}
+}
+
+func mustMarshal(t *testing.T, value any, params string) []byte {
+	t.Helper()
+
+	data, err := asn1.MarshalWithParams(value, params)
+	if err != nil {
+		t.Fatalf("marshaling %T: %v", value, err)
+	}
+
+	return data
+}
+
+// Creates a signature file listing hashes of the files under their numbers.
+// If embed is true, the certificate of the signer is included in the signature.
+func (signer testSigner) sign(t *testing.T, embed bool, files ...signedFile) []byte {
+	t.Helper()
+
+	object := securityObject{HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256}}
+	for _, file := range files {
+		object.Hashes = append(object.Hashes, securityObjectHash{Number: file.number, Hash: hashData(crypto.SHA256, file.data)})
+	}
+	content := mustMarshal(t, object, "")
+
+	digest := mustMarshal(t, hashData(crypto.SHA256, content), "")
+	attributes := mustMarshal(t, []cmsAttribute{{Type: oidMessageDigest, Values: []asn1.RawValue{{FullBytes: digest}}}}, "set")
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, signer.key, crypto.SHA256, hashData(crypto.SHA256, attributes))
+	if err != nil {
+		t.Fatalf("signing: %v", err)
+	}
+
+	signedAttributes := append([]byte{0xA0}, attributes[1:]...)
+
+	signedData := cmsSignedData{
+		Version:          3,
+		DigestAlgorithms: asn1.RawValue{FullBytes: mustMarshal(t, []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}}, "set")},
+		EncapContentInfo: cmsEncapsulatedContentInfo{
+			ContentType: asn1.ObjectIdentifier{2, 23, 136, 1, 1, 1},
+			Content:     content,
+		},
+		SignerInfos: []cmsSignerInfo{{
+			Version:            1,
+			SID:                asn1.RawValue{FullBytes: mustMarshal(t, signer.certificate.SerialNumber, "")},
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttributes:   asn1.RawValue{FullBytes: signedAttributes},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}},
+			Signature:          signature,
+		}},
+	}
+
+	if embed {
+		signedData.Certificates = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signer.certificate.Raw}
+	}
+
+	contentInfo := cmsContentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: mustMarshal(t, signedData, "")},
+	}
+
+	return mustMarshal(t, contentInfo, "")
+}
+
+// Creates a certificate authority and a document signer issued by it.
+func newTestChain(t *testing.T) (testSigner, testSigner) {
+	t.Helper()
+
+	ca := newTestSigner(t)
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(10),
+		Subject:               pkix.Name{CommonName: "Test root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &ca.key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("creating root: %v", err)
+	}
+
+	ca.certificate, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing root: %v", err)
+	}
+
+	signer := newTestSigner(t)
+	template = x509.Certificate{
+		SerialNumber: big.NewInt(11),
+		Subject:      pkix.Name{CommonName: "Document signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	der, err = x509.CreateCertificate(rand.Reader, &template, ca.certificate, &signer.key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("creating signer: %v", err)
+	}
+
+	signer.certificate, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing signer: %v", err)
+	}
+
+	return ca, signer
+}
+
+func TestVerifyFiles(t *testing.T) {
+	ca, signer := newTestChain(t)
+	_, untrusted := newTestChain(t)
+
+	documentFile := signedFile{name: "document", number: 2, data: []byte{0x01, 0x02}}
+	personalFile := signedFile{name: "personal", number: 3, data: []byte{0x03, 0x04}}
+	residenceFile := signedFile{name: "residence", number: 4, data: []byte{0x05, 0x06}}
+	photoFile := signedFile{name: "photo", number: 6, data: []byte{0x07, 0x08}}
+
+	files := []signedFile{documentFile, personalFile, residenceFile, photoFile}
+	opts := trust.Options{Roots: []*x509.Certificate{ca.certificate}}
+
+	// The residence file can change, so it is signed separately
+	fixed := signer.sign(t, true, documentFile, personalFile, photoFile)
+	residence := signer.sign(t, true, residenceFile)
+
+	swapped := []signedFile{
+		{number: documentFile.number, data: personalFile.data},
+		{number: personalFile.number, data: documentFile.data},
+		photoFile, residenceFile,
+	}
+
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: time.Now().Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{
+			{SerialNumber: signer.certificate.SerialNumber, RevocationTime: time.Now().Add(-time.Minute)},
+		},
+	}, ca.certificate, ca.key)
+	if err != nil {
+		t.Fatalf("creating CRL: %v", err)
+	}
+
+	revocationList, err := x509.ParseRevocationList(crl)
+	if err != nil {
+		t.Fatalf("parsing CRL: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		signatures   [][]byte
+		certificates []*x509.Certificate
+		opts         trust.Options
+		files        []signedFile
+		status       string
+	}{
+		{
+			name:       "matching data",
+			signatures: [][]byte{fixed, residence},
+			opts:       opts,
+			files:      files,
+			status:     document.VerificationUnconfirmed,
+		},
+		{
+			name:         "signer from the card",
+			signatures:   [][]byte{signer.sign(t, false, documentFile, personalFile, photoFile, residenceFile), nil},
+			certificates: []*x509.Certificate{untrusted.certificate, signer.certificate},
+			opts:         opts,
+			files:        files,
+			status:       document.VerificationUnconfirmed,
+		},
+		{
+			name:       "embedded signer without trusted roots",
+			signatures: [][]byte{fixed, residence},
+			files:      files,
+			status:     document.VerificationUnavailable,
+		},
+		{
+			name:       "embedded signer from another root",
+			signatures: [][]byte{untrusted.sign(t, true, files...), nil},
+			opts:       opts,
+			files:      files,
+			status:     document.VerificationUnavailable,
+		},
+		{
+			name:       "embedded self-signed signer",
+			signatures: [][]byte{newTestSigner(t).sign(t, true, files...), nil},
+			opts:       opts,
+			files:      files,
+			status:     document.VerificationUnavailable,
+		},
+		{
+			name:       "revoked signer",
+			signatures: [][]byte{fixed, residence},
+			opts:       trust.Options{Roots: opts.Roots, CRLs: []*x509.RevocationList{revocationList}},
+			files:      files,
+			status:     document.VerificationInvalid,
+		},
+		{
+			name:       "modified file",
+			signatures: [][]byte{fixed, residence},
+			opts:       opts,
+			files: []signedFile{
+				documentFile,
+				{name: "personal", number: 3, data: []byte{0x03, 0x05}},
+				residenceFile,
+				photoFile,
+			},
+			status: document.VerificationInvalid,
+		},
+		{
+			name:       "files listed under each other's numbers",
+			signatures: [][]byte{signer.sign(t, true, swapped...), nil},
+			opts:       opts,
+			files:      files,
+			status:     document.VerificationInvalid,
+		},
+		{
+			name:       "unlisted file",
+			signatures: [][]byte{fixed, nil},
+			opts:       opts,
+			files:      files,
+			status:     document.VerificationUnavailable,
+		},
+		{
+			name:         "wrong certificate",
+			signatures:   [][]byte{signer.sign(t, false, documentFile), nil},
+			certificates: []*x509.Certificate{untrusted.certificate},
+			opts:         opts,
+			files:        files,
+			status:       document.VerificationInvalid,
+		},
+		{
+			name:       "no certificate",
+			signatures: [][]byte{signer.sign(t, false, documentFile), nil},
+			opts:       opts,
+			files:      files,
+			status:     document.VerificationUnavailable,
+		},
+		{
+			name:       "signatures not read",
+			signatures: [][]byte{nil, nil},
+			opts:       opts,
+			files:      files,
+			status:     document.VerificationUnavailable,
+		},
+		{
+			name:       "malformed signature",
+			signatures: [][]byte{{0x30, 0x03, 0x02, 0x01}, nil},
+			opts:       opts,
+			files:      files,
+			status:     document.VerificationUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification := verifyFiles(tt.signatures, tt.certificates, tt.opts, tt.files)
+			if verification.Status != tt.status {
+				t.Errorf("expected status %s, got %+v", tt.status, verification)
+			}
+
+			if verification.Error == "" {
+				t.Error("expected error description")
+			}
+		})
+	}
+}
+
+func TestVerifyFiles_TamperedSignedContent(t *testing.T) {
+	ca, signer := newTestChain(t)
+	file := signedFile{name: "document", number: 2, data: []byte{0x01}}
+
+	signature := signer.sign(t, true, file)
+	// Flip a bit of the listed hash, which is located before the certificate
+	hash := hashData(crypto.SHA256, file.data)
+	for i := range signature {
+		if i+len(hash) <= len(signature) && string(signature[i:i+len(hash)]) == string(hash) {
+			signature[i] ^= 0x01
+			break
+		}
+	}
+
+	opts := trust.Options{Roots: []*x509.Certificate{ca.certificate}}
+	verification := verifyFiles([][]byte{signature}, nil, opts, []signedFile{file})
+	if verification.Status != document.VerificationInvalid {
+		t.Errorf("expected invalid status, got %+v", verification)
+	}
+}
+
+func TestGemalto_Verify(t *testing.T) {
+	gemalto := Gemalto{atr: GEMALTO_ATR_3}
+
+	if verification := gemalto.verify(); verification != nil {
+		t.Errorf("expected no verification without signatures, got %+v", verification)
+	}
+
+	ca, signer := newTestChain(t)
+	gemalto.documentFile = []byte{0x01}
+	gemalto.personalFile = []byte{0x02}
+	gemalto.residenceFile = []byte{0x03}
+	gemalto.rawPhotoFile = []byte{0x04, 0x05, 0x06, 0x07, 0x08}
+	gemalto.signature[0] = signer.sign(t, false,
+		signedFile{number: 2, data: gemalto.documentFile},
+		signedFile{number: 3, data: gemalto.personalFile},
+		signedFile{number: 6, data: gemalto.rawPhotoFile},
+	)
+	gemalto.signature[1] = signer.sign(t, false, signedFile{number: 4, data: gemalto.residenceFile})
+	gemalto.certificates = []*x509.Certificate{signer.certificate}
+	gemalto.trustOptions = trust.Options{Roots: []*x509.Certificate{ca.certificate}}
+
+	verification := gemalto.verify()
+	if verification == nil || verification.Status != document.VerificationUnconfirmed {
+		t.Errorf("expected matching data, got %+v", verification)
+	}
+}
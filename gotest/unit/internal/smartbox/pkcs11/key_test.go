@@ -0,0 +1,213 @@
+package pkcs11
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+)
+
+func generateRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	return key
+}
+
+func TestSessionKey(t *testing.T) {
+	rsaKey := generateRSAKey(t)
+	session := &PkcsModuleSession{}
+
+	key, err := session.Key(NamedCert{ID: []byte{0x01}, Certificate: &x509.Certificate{PublicKey: &rsaKey.PublicKey}})
+	if err != nil {
+		t.Fatalf("Key() unexpected error: %v", err)
+	}
+
+	if publicKey, ok := key.Public().(*rsa.PublicKey); !ok || !publicKey.Equal(&rsaKey.PublicKey) {
+		t.Errorf("Public() returned unexpected key %v", key.Public())
+	}
+
+	publicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := session.Key(NamedCert{Certificate: &x509.Certificate{PublicKey: publicKey}}); err == nil {
+		t.Error("Key() expected error for Ed25519 key")
+	}
+
+	if _, err := session.Key(NamedCert{}); err == nil {
+		t.Error("Key() expected error without certificate")
+	}
+}
+
+func TestSignMechanism(t *testing.T) {
+	rsaKey := generateRSAKey(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	digest := sha256.Sum256([]byte("data"))
+	sha256Prefix, _ := hex.DecodeString("3031300d060960864801650304020105000420")
+
+	tests := []struct {
+		name      string
+		publicKey crypto.PublicKey
+		digest    []byte
+		opts      crypto.SignerOpts
+		mechanism uint
+		parameter []byte
+		data      []byte
+	}{
+		{
+			name:      "PKCS #1 v1.5",
+			publicKey: &rsaKey.PublicKey,
+			digest:    digest[:],
+			opts:      crypto.SHA256,
+			mechanism: pkcs11.CKM_RSA_PKCS,
+			data:      append(append([]byte{}, sha256Prefix...), digest[:]...),
+		},
+		{
+			name:      "PKCS #1 v1.5 with encoded digest",
+			publicKey: &rsaKey.PublicKey,
+			digest:    []byte{0x01, 0x02, 0x03},
+			opts:      crypto.Hash(0),
+			mechanism: pkcs11.CKM_RSA_PKCS,
+			data:      []byte{0x01, 0x02, 0x03},
+		},
+		{
+			name:      "PSS with hash length salt",
+			publicKey: &rsaKey.PublicKey,
+			digest:    digest[:],
+			opts:      &rsa.PSSOptions{Hash: crypto.SHA256, SaltLength: rsa.PSSSaltLengthEqualsHash},
+			mechanism: pkcs11.CKM_RSA_PKCS_PSS,
+			parameter: pkcs11.NewPSSParams(pkcs11.CKM_SHA256, pkcs11.CKG_MGF1_SHA256, 32),
+			data:      digest[:],
+		},
+		{
+			name:      "PSS with maximal salt",
+			publicKey: &rsaKey.PublicKey,
+			digest:    digest[:],
+			opts:      &rsa.PSSOptions{Hash: crypto.SHA256, SaltLength: rsa.PSSSaltLengthAuto},
+			mechanism: pkcs11.CKM_RSA_PKCS_PSS,
+			parameter: pkcs11.NewPSSParams(pkcs11.CKM_SHA256, pkcs11.CKG_MGF1_SHA256, 256-2-32),
+			data:      digest[:],
+		},
+		{
+			name:      "ECDSA",
+			publicKey: &ecKey.PublicKey,
+			digest:    digest[:],
+			opts:      crypto.SHA256,
+			mechanism: pkcs11.CKM_ECDSA,
+			data:      digest[:],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mechanism, data, err := signMechanism(tt.publicKey, tt.digest, tt.opts)
+			if err != nil {
+				t.Fatalf("signMechanism() unexpected error: %v", err)
+			}
+
+			if mechanism.Mechanism != tt.mechanism {
+				t.Errorf("expected mechanism %X, got %X", tt.mechanism, mechanism.Mechanism)
+			}
+
+			if !bytes.Equal(mechanism.Parameter, tt.parameter) {
+				t.Errorf("expected parameter %X, got %X", tt.parameter, mechanism.Parameter)
+			}
+
+			if !bytes.Equal(data, tt.data) {
+				t.Errorf("expected data %X, got %X", tt.data, data)
+			}
+		})
+	}
+}
+
+func TestSignMechanism_Errors(t *testing.T) {
+	rsaKey := generateRSAKey(t)
+	digest := sha256.Sum256([]byte("data"))
+	publicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	if _, _, err := signMechanism(&rsaKey.PublicKey, digest[:20], crypto.SHA256); err == nil {
+		t.Error("expected error for wrong digest length")
+	}
+
+	if _, _, err := signMechanism(&rsaKey.PublicKey, make([]byte, 16), crypto.MD5); err == nil {
+		t.Error("expected error for unsupported hash")
+	}
+
+	if _, _, err := signMechanism(publicKey, digest[:], crypto.SHA256); err == nil {
+		t.Error("expected error for unsupported key")
+	}
+}
+
+func TestDecryptMechanism(t *testing.T) {
+	rsaKey := generateRSAKey(t)
+
+	mechanism, err := decryptMechanism(&rsaKey.PublicKey, nil)
+	if err != nil || mechanism.Mechanism != pkcs11.CKM_RSA_PKCS {
+		t.Errorf("expected PKCS #1 v1.5 mechanism, got %v, %v", mechanism, err)
+	}
+
+	mechanism, err = decryptMechanism(&rsaKey.PublicKey, &rsa.PKCS1v15DecryptOptions{})
+	if err != nil || mechanism.Mechanism != pkcs11.CKM_RSA_PKCS {
+		t.Errorf("expected PKCS #1 v1.5 mechanism, got %v, %v", mechanism, err)
+	}
+
+	mechanism, err = decryptMechanism(&rsaKey.PublicKey, &rsa.OAEPOptions{Hash: crypto.SHA256, Label: []byte("label")})
+	if err != nil || mechanism.Mechanism != pkcs11.CKM_RSA_PKCS_OAEP {
+		t.Errorf("expected OAEP mechanism, got %v, %v", mechanism, err)
+	}
+
+	if _, err := decryptMechanism(&rsaKey.PublicKey, &rsa.OAEPOptions{Hash: crypto.MD5}); err == nil {
+		t.Error("expected error for unsupported OAEP hash")
+	}
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := decryptMechanism(&ecKey.PublicKey, nil); err == nil {
+		t.Error("expected error for ECDSA key")
+	}
+}
+
+func TestEcdsaSignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	digest := sha256.Sum256([]byte("data"))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("signing: %v", err)
+	}
+
+	// PKCS#11 returns r and s padded to the size of the curve
+	raw := make([]byte, 64)
+	r.FillBytes(raw[:32])
+	s.FillBytes(raw[32:])
+
+	signature, err := ecdsaSignature(raw)
+	if err != nil {
+		t.Fatalf("ecdsaSignature() unexpected error: %v", err)
+	}
+
+	if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], signature) {
+		t.Error("converted signature not valid")
+	}
+
+	if _, err := ecdsaSignature([]byte{0x01, 0x02, 0x03}); err == nil {
+		t.Error("expected error for odd signature length")
+	}
+}
@@ -0,0 +1,63 @@
+#!/bin/bash
+
+# Applies the patches from this directory to the bas-celik source, in order.
+# Usage: ./patch/apply_patches.sh <path to bas-celik>
+
+PATCH_DIR="$(cd "$(dirname "$0")" && pwd)"
+CODE_PATH="$1"
+
+if [ ! -d "$CODE_PATH" ]; then
+    echo "Error: Could not find bas-celik directory"
+    exit 1
+fi
+
+PATCHES=(
+    "golangci_lint.patch"
+    "gocyclo.patch"
+    "gotest.patch"
+    "transcript.patch"
+    "virtual_card.patch"
+    "apdu_transport.patch"
+    "response_apdu.patch"
+    "driver_registry.patch"
+    "atr_parse.patch"
+    "atr_pattern.patch"
+    "card_probe.patch"
+    "read_progress.patch"
+    "card_context.patch"
+    "id_verification.patch"
+    "card_trust.patch"
+    "gemalto_pin.patch"
+    "gemalto_signer.patch"
+    "pkcs11_key.patch"
+    "raw_fields.patch"
+    "vehicle_extra.patch"
+    "crypto_card.patch"
+    "multi_application.patch"
+    "read_cache.patch"
+    "tlv_encode.patch"
+    "ber_encode.patch"
+    "ber_query.patch"
+    "ber_limits.patch"
+    "tlv_parse.patch"
+    "transcript_record.patch"
+    "virtual_card_extended.patch"
+    "apdu_chaining.patch"
+    "read_status.patch"
+    "id_verification_trust.patch"
+    "card_trust_roots.patch"
+    "gemalto_pin_tries.patch"
+    "gemalto_signer_prkdf.patch"
+    "pkcs1_digest_info.patch"
+    "vehicle_extra_indices.patch"
+    "ber_long_tags.patch"
+    "tlv_file_warnings.patch"
+)
+
+for patch_name in "${PATCHES[@]}"; do
+    echo "Applying ${patch_name}..."
+    if ! git -C "$CODE_PATH" apply "${PATCH_DIR}/${patch_name}"; then
+        echo "Error: ${patch_name} does not apply"
+        exit 1
+    fi
+done